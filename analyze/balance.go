@@ -0,0 +1,218 @@
+package analyze
+
+import (
+	"math"
+	"sort"
+)
+
+// ShardPlacement is an assigned shard and the node holding it.
+type ShardPlacement struct {
+	Index   string
+	Node    string
+	Primary bool
+	Store   float64
+}
+
+// GroupBalance is the number of shards, primaries and the store size held by a node or by
+// the nodes sharing a node attribute value.
+type GroupBalance struct {
+	Group     string
+	Nodes     int
+	Shards    int
+	Primaries int
+	Store     float64
+}
+
+// Balance groups the shards by the group of their node. Nodes without a group are left
+// out. The given nodes are counted even when they hold no shards.
+func Balance(placements []ShardPlacement, group func(node string) (string, bool), nodes []string) []GroupBalance {
+	groups := make(map[string]*GroupBalance)
+	nodeGroups := make(map[string]map[string]bool)
+
+	get := func(node string) *GroupBalance {
+		name, ok := group(node)
+		if !ok {
+			return nil
+		}
+		if groups[name] == nil {
+			groups[name] = &GroupBalance{Group: name}
+			nodeGroups[name] = make(map[string]bool)
+		}
+		nodeGroups[name][node] = true
+		return groups[name]
+	}
+
+	for _, node := range nodes {
+		get(node)
+	}
+
+	for _, placement := range placements {
+		balance := get(placement.Node)
+		if balance == nil {
+			continue
+		}
+		balance.Shards++
+		if placement.Primary {
+			balance.Primaries++
+		}
+		balance.Store += placement.Store
+	}
+
+	result := make([]GroupBalance, 0, len(groups))
+	for name, balance := range groups {
+		balance.Nodes = len(nodeGroups[name])
+		result = append(result, *balance)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Group < result[j].Group })
+	return result
+}
+
+// Deviations returns how many sample standard deviations every value is away from the
+// mean of the values. The deviations of n values can not exceed (n-1)/√n, just under
+// √(n-1), so two values never deviate by more than 0.71 and three by more than 1.15. All
+// deviations are zero when there are fewer than two values or the values are equal.
+func Deviations(values []float64) []float64 {
+	deviations := make([]float64, len(values))
+	if len(values) < 2 {
+		return deviations
+	}
+
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(values)-1))
+	if stddev == 0 {
+		return deviations
+	}
+
+	for i, value := range values {
+		deviations[i] = (value - mean) / stddev
+	}
+	return deviations
+}
+
+// ShardsPerNode is the average number of shards on the nodes of the group.
+func (b GroupBalance) ShardsPerNode() float64 {
+	if b.Nodes == 0 {
+		return 0
+	}
+	return float64(b.Shards) / float64(b.Nodes)
+}
+
+// StorePerNode is the average store size on the nodes of the group.
+func (b GroupBalance) StorePerNode() float64 {
+	if b.Nodes == 0 {
+		return 0
+	}
+	return b.Store / float64(b.Nodes)
+}
+
+// IndexSkew describes how evenly the shards of an index are spread over the nodes.
+type IndexSkew struct {
+	Index     string
+	Shards    int
+	Primaries int
+	// Nodes is the number of nodes the shards can be spread over.
+	Nodes int
+	// TopNode is the node holding the most primaries of the index, and TopPrimaries their
+	// number.
+	TopNode      string
+	TopPrimaries int
+	// MaxShards is the highest number of shards of the index on a node.
+	MaxShards int
+}
+
+// IdealPrimaries is the highest number of primaries on a node when they are spread evenly.
+func (s IndexSkew) IdealPrimaries() int {
+	return ceilDiv(s.Primaries, s.Nodes)
+}
+
+// IdealShards is the highest number of shards on a node when they are spread evenly.
+func (s IndexSkew) IdealShards() int {
+	return ceilDiv(s.Shards, s.Nodes)
+}
+
+// Skewed reports whether a node holds more primaries or shards of the index than it would
+// if they were spread evenly.
+func (s IndexSkew) Skewed() bool {
+	return s.TopPrimaries > s.IdealPrimaries() || s.MaxShards > s.IdealShards()
+}
+
+// Skews returns the skew of every index, the most skewed first. The tiers map the data
+// nodes to their tier, and the shards of an index can be spread over the nodes of the
+// tiers of the nodes holding them. Nodes without a tier are tiers of their own.
+func Skews(placements []ShardPlacement, tiers map[string]string) []IndexSkew {
+	tierNodes := make(map[string]int)
+	for _, tier := range tiers {
+		tierNodes[tier]++
+	}
+
+	type counts struct {
+		shards    map[string]int
+		primaries map[string]int
+	}
+
+	indices := make(map[string]*counts)
+	for _, placement := range placements {
+		c := indices[placement.Index]
+		if c == nil {
+			c = &counts{shards: make(map[string]int), primaries: make(map[string]int)}
+			indices[placement.Index] = c
+		}
+		c.shards[placement.Node]++
+		if placement.Primary {
+			c.primaries[placement.Node]++
+		}
+	}
+
+	skews := make([]IndexSkew, 0, len(indices))
+	for index, c := range indices {
+		skew := IndexSkew{Index: index}
+		indexTiers := make(map[string]bool)
+		for node, shards := range c.shards {
+			if tier, ok := tiers[node]; !ok {
+				skew.Nodes++
+			} else if !indexTiers[tier] {
+				indexTiers[tier] = true
+				skew.Nodes += tierNodes[tier]
+			}
+
+			skew.Shards += shards
+			if shards > skew.MaxShards {
+				skew.MaxShards = shards
+			}
+
+			primaries := c.primaries[node]
+			skew.Primaries += primaries
+			if primaries > skew.TopPrimaries || (primaries == skew.TopPrimaries && primaries > 0 && node < skew.TopNode) {
+				skew.TopNode, skew.TopPrimaries = node, primaries
+			}
+		}
+		skews = append(skews, skew)
+	}
+
+	excess := func(s IndexSkew) int {
+		return s.TopPrimaries - s.IdealPrimaries() + s.MaxShards - s.IdealShards()
+	}
+	sort.Slice(skews, func(i, j int) bool {
+		if excess(skews[i]) != excess(skews[j]) {
+			return excess(skews[i]) > excess(skews[j])
+		}
+		return skews[i].Index < skews[j].Index
+	})
+	return skews
+}
+
+func ceilDiv(a, b int) int {
+	if b <= 0 {
+		return a
+	}
+	return (a + b - 1) / b
+}
@@ -0,0 +1,118 @@
+package analyze
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var placements = []ShardPlacement{
+	{Index: "logs", Node: "a", Primary: true, Store: 10},
+	{Index: "logs", Node: "a", Primary: true, Store: 10},
+	{Index: "logs", Node: "b", Primary: false, Store: 10},
+	{Index: "logs", Node: "b", Primary: false, Store: 10},
+	{Index: "orders", Node: "a", Primary: true, Store: 5},
+	{Index: "orders", Node: "b", Primary: false, Store: 5},
+}
+
+func TestBalance(t *testing.T) {
+	racks := map[string]string{"a": "r1", "b": "r1", "c": "r2"}
+	byRack := func(node string) (string, bool) {
+		rack, ok := racks[node]
+		return rack, ok
+	}
+
+	expected := []GroupBalance{
+		{Group: "r1", Nodes: 2, Shards: 6, Primaries: 3, Store: 50},
+		{Group: "r2", Nodes: 1},
+	}
+	if actual := Balance(placements, byRack, []string{"a", "b", "c", "d"}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	byNode := func(node string) (string, bool) { return node, true }
+	expected = []GroupBalance{
+		{Group: "a", Nodes: 1, Shards: 3, Primaries: 3, Store: 25},
+		{Group: "b", Nodes: 1, Shards: 3, Primaries: 0, Store: 25},
+	}
+	if actual := Balance(placements, byNode, nil); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	balance := GroupBalance{Nodes: 2, Shards: 6, Store: 50}
+	if balance.ShardsPerNode() != 3 || balance.StorePerNode() != 25 {
+		t.Errorf("expected 3 shards and 25 bytes per node, got %v and %v", balance.ShardsPerNode(), balance.StorePerNode())
+	}
+	if empty := (GroupBalance{}); empty.ShardsPerNode() != 0 || empty.StorePerNode() != 0 {
+		t.Errorf("expected nothing per node without nodes, got %+v", empty)
+	}
+}
+
+func TestDeviations(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		expected []float64
+	}{
+		{"empty", nil, []float64{}},
+		{"single", []float64{5}, []float64{0}},
+		{"equal", []float64{3, 3, 3}, []float64{0, 0, 0}},
+		{"two values", []float64{1, 3}, []float64{-1 / math.Sqrt2, 1 / math.Sqrt2}},
+		{"spread", []float64{2, 4, 6}, []float64{-1, 0, 1}},
+		// A single outlier among n values deviates by (n-1)/√n.
+		{"outlier", []float64{0, 0, 0, 0, 10}, []float64{-1 / math.Sqrt(5), -1 / math.Sqrt(5), -1 / math.Sqrt(5), -1 / math.Sqrt(5), 4 / math.Sqrt(5)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := Deviations(test.values)
+			if len(actual) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, actual)
+			}
+			for i := range actual {
+				if math.Abs(actual[i]-test.expected[i]) > 1e-9 {
+					t.Errorf("expected %v, got %v", test.expected, actual)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSkews(t *testing.T) {
+	skews := Skews(placements, map[string]string{"a": "h", "b": "h", "c": "w"})
+	if len(skews) != 2 {
+		t.Fatalf("expected 2 indices, got %d", len(skews))
+	}
+
+	logs := skews[0]
+	expected := IndexSkew{Index: "logs", Shards: 4, Primaries: 2, Nodes: 2, TopNode: "a", TopPrimaries: 2, MaxShards: 2}
+	if logs != expected {
+		t.Errorf("expected %+v, got %+v", expected, logs)
+	}
+	if !logs.Skewed() || logs.IdealPrimaries() != 1 || logs.IdealShards() != 2 {
+		t.Errorf("expected logs to be skewed with 1 ideal primary and 2 ideal shards, got %+v", logs)
+	}
+
+	if orders := skews[1]; orders.Index != "orders" || orders.Skewed() {
+		t.Errorf("expected orders not to be skewed, got %+v", orders)
+	}
+
+	// The shards of the hot index can be spread over the two hot nodes only, while the
+	// shards of the index on a generic data node can be spread over all data nodes.
+	tiered := []ShardPlacement{
+		{Index: "hot", Node: "a", Primary: true},
+		{Index: "hot", Node: "b", Primary: true},
+		{Index: "all", Node: "d", Primary: true},
+		{Index: "all", Node: "d", Primary: true},
+		{Index: "unknown", Node: "x", Primary: true},
+	}
+	tiers := map[string]string{"a": "h", "b": "h", "c": "w", "d": "d", "e": "d", "f": "d"}
+	nodes := make(map[string]int)
+	for _, skew := range Skews(tiered, tiers) {
+		nodes[skew.Index] = skew.Nodes
+	}
+	if expected := map[string]int{"hot": 2, "all": 3, "unknown": 1}; !reflect.DeepEqual(nodes, expected) {
+		t.Errorf("expected nodes %v, got %v", expected, nodes)
+	}
+}
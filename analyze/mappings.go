@@ -0,0 +1,152 @@
+package analyze
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultTotalFieldsLimit is the default of index.mapping.total_fields.limit.
+const DefaultTotalFieldsLimit = 1000
+
+// MappingStats describes the size and the shape of the mappings of an index.
+type MappingStats struct {
+	Index string
+	// TotalFields counts the fields as index.mapping.total_fields.limit does: object and
+	// nested fields, leaf fields and multi-fields.
+	TotalFields   int
+	Limit         int
+	Depth         int
+	NestedObjects int
+	Dynamic       string
+	// ObjectFields is the number of fields under every object path, at any depth.
+	ObjectFields map[string]int
+}
+
+// Usage returns the total fields as a percentage of the limit.
+func (s MappingStats) Usage() float64 {
+	if s.Limit <= 0 {
+		return 0
+	}
+	return float64(s.TotalFields) * 100 / float64(s.Limit)
+}
+
+// ObjectCount is the number of fields under an object path.
+type ObjectCount struct {
+	Path   string
+	Fields int
+}
+
+// ObjectGrowth is the change of the number of fields under an object path.
+type ObjectGrowth struct {
+	Path string
+	From int
+	To   int
+}
+
+func (g ObjectGrowth) Growth() int {
+	return g.To - g.From
+}
+
+// AnalyzeMappings returns the statistics of the mappings of an index.
+func AnalyzeMappings(index string, mappings interface{}, limit int) MappingStats {
+	stats := MappingStats{
+		Index:        index,
+		Limit:        limit,
+		Dynamic:      "true",
+		ObjectFields: make(map[string]int),
+	}
+
+	if m, ok := mappings.(map[string]interface{}); ok {
+		if dynamic, ok := m["dynamic"]; ok {
+			stats.Dynamic = fmt.Sprint(dynamic)
+		}
+		stats.TotalFields = countProperties(m["properties"], "", 1, &stats)
+	}
+
+	return stats
+}
+
+// countProperties returns the number of fields in the properties and records the depth,
+// the nested objects and the fields of every object.
+func countProperties(properties interface{}, parent string, depth int, stats *MappingStats) int {
+	props, ok := properties.(map[string]interface{})
+	if !ok || len(props) == 0 {
+		return 0
+	}
+
+	if depth > stats.Depth {
+		stats.Depth = depth
+	}
+
+	count := 0
+	for name, value := range props {
+		definition, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		path := name
+		if parent != "" {
+			path = parent + "." + name
+		}
+
+		count++
+		if multiFields, ok := definition["fields"].(map[string]interface{}); ok {
+			count += len(multiFields)
+		}
+
+		if children, ok := definition["properties"]; ok {
+			if definition["type"] == "nested" {
+				stats.NestedObjects++
+			}
+			fields := countProperties(children, path, depth+1, stats)
+			stats.ObjectFields[path] = fields
+			count += fields
+		}
+	}
+
+	return count
+}
+
+// TopObjects returns the n object paths with the most fields.
+func TopObjects(objectFields map[string]int, n int) []ObjectCount {
+	objects := make([]ObjectCount, 0, len(objectFields))
+	for path, fields := range objectFields {
+		objects = append(objects, ObjectCount{Path: path, Fields: fields})
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Fields != objects[j].Fields {
+			return objects[i].Fields > objects[j].Fields
+		}
+		return objects[i].Path < objects[j].Path
+	})
+
+	if len(objects) > n {
+		objects = objects[:n]
+	}
+	return objects
+}
+
+// FastestGrowing returns the n object paths whose number of fields grew the most from the
+// oldest to the newest mappings.
+func FastestGrowing(oldest, newest MappingStats, n int) []ObjectGrowth {
+	var growths []ObjectGrowth
+	for path, to := range newest.ObjectFields {
+		if from := oldest.ObjectFields[path]; to > from {
+			growths = append(growths, ObjectGrowth{Path: path, From: from, To: to})
+		}
+	}
+
+	sort.Slice(growths, func(i, j int) bool {
+		if growths[i].Growth() != growths[j].Growth() {
+			return growths[i].Growth() > growths[j].Growth()
+		}
+		return growths[i].Path < growths[j].Path
+	})
+
+	if len(growths) > n {
+		growths = growths[:n]
+	}
+	return growths
+}
@@ -0,0 +1,71 @@
+package analyze
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeMappings(t *testing.T) {
+	mappings := map[string]interface{}{
+		"dynamic": "strict",
+		"properties": map[string]interface{}{
+			"title": map[string]interface{}{
+				"type":   "text",
+				"fields": map[string]interface{}{"raw": map[string]interface{}{"type": "keyword"}},
+			},
+			"user": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{"type": "keyword"},
+					"address": map[string]interface{}{
+						"properties": map[string]interface{}{
+							"city": map[string]interface{}{"type": "keyword"},
+						},
+					},
+				},
+			},
+			"comments": map[string]interface{}{
+				"type": "nested",
+				"properties": map[string]interface{}{
+					"text": map[string]interface{}{"type": "text"},
+				},
+			},
+		},
+	}
+
+	stats := AnalyzeMappings("articles", mappings, 10)
+
+	// title, title.raw, user, user.name, user.address, user.address.city, comments, comments.text
+	if stats.TotalFields != 8 {
+		t.Errorf("expected 8 fields, got %d", stats.TotalFields)
+	}
+	if stats.Usage() != 80 {
+		t.Errorf("expected 80%% usage, got %f", stats.Usage())
+	}
+	if stats.Depth != 3 {
+		t.Errorf("expected depth 3, got %d", stats.Depth)
+	}
+	if stats.NestedObjects != 1 {
+		t.Errorf("expected 1 nested object, got %d", stats.NestedObjects)
+	}
+	if stats.Dynamic != "strict" {
+		t.Errorf("expected dynamic strict, got %s", stats.Dynamic)
+	}
+
+	expected := []ObjectCount{{Path: "user", Fields: 3}, {Path: "comments", Fields: 1}}
+	if objects := TopObjects(stats.ObjectFields, 2); !reflect.DeepEqual(objects, expected) {
+		t.Errorf("expected top objects %v, got %v", expected, objects)
+	}
+}
+
+func TestFastestGrowing(t *testing.T) {
+	oldest := MappingStats{ObjectFields: map[string]int{"attributes": 10, "labels": 5, "user": 3}}
+	newest := MappingStats{ObjectFields: map[string]int{"attributes": 500, "labels": 5, "user": 2, "extra": 20}}
+
+	expected := []ObjectGrowth{
+		{Path: "attributes", From: 10, To: 500},
+		{Path: "extra", From: 0, To: 20},
+	}
+	if growths := FastestGrowing(oldest, newest, 5); !reflect.DeepEqual(growths, expected) {
+		t.Errorf("expected growths %v, got %v", expected, growths)
+	}
+}
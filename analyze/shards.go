@@ -0,0 +1,128 @@
+package analyze
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultTargetShardSize is the shard size the primaries are sized for by default.
+const DefaultTargetShardSize = 40 * 1024 * 1024 * 1024
+
+// MaxShardsPerHeapGB is the recommended maximum number of shards per GB of heap on a node.
+const MaxShardsPerHeapGB = 20
+
+// Distribution summarises a set of values.
+type Distribution struct {
+	Count  int
+	Min    float64
+	Median float64
+	P90    float64
+	Max    float64
+}
+
+// NewDistribution returns the distribution of the values.
+func NewDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	return Distribution{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Median: percentile(sorted, 50),
+		P90:    percentile(sorted, 90),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// IndexSize is the primary store size of an index of a pattern.
+type IndexSize struct {
+	Index        string
+	Created      time.Time
+	Primaries    int
+	PrimaryBytes float64
+}
+
+// Growth is the observed growth of the indices of a pattern.
+type Growth struct {
+	Indices int
+	// Interval is the average time between the creation of two consecutive indices, or
+	// the age of the index when the pattern matches a single index.
+	Interval time.Duration
+	// DailyBytes is the primary data added per day.
+	DailyBytes float64
+	// Newest is the most recently created index.
+	Newest IndexSize
+}
+
+// ObserveGrowth returns the growth of the indices of a pattern at the given time.
+func ObserveGrowth(indices []IndexSize, now time.Time) Growth {
+	if len(indices) == 0 {
+		return Growth{}
+	}
+
+	sorted := append([]IndexSize(nil), indices...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Created.Before(sorted[j].Created)
+	})
+
+	oldest, newest := sorted[0], sorted[len(sorted)-1]
+	growth := Growth{Indices: len(sorted), Newest: newest}
+
+	// The newest index is still being written to, so the growth is measured on the older
+	// indices over the time until the newest was created.
+	complete, until := sorted[:len(sorted)-1], newest.Created
+	if len(sorted) == 1 {
+		complete, until = sorted, now
+	}
+
+	growth.Interval = until.Sub(oldest.Created)
+	if len(complete) > 1 {
+		growth.Interval /= time.Duration(len(complete))
+	}
+
+	total := 0.0
+	for _, index := range complete {
+		total += index.PrimaryBytes
+	}
+	if days := until.Sub(oldest.Created).Hours() / 24; days > 0 {
+		growth.DailyBytes = total / days
+	}
+
+	return growth
+}
+
+// IndexBytes returns the primary data expected in an index over its interval.
+func (g Growth) IndexBytes() float64 {
+	return g.DailyBytes * g.Interval.Hours() / 24
+}
+
+// RecommendPrimaries returns the number of primaries keeping the shards of an index at or
+// below the target size, and at least one.
+func RecommendPrimaries(indexBytes, targetBytes float64) int {
+	if targetBytes <= 0 || indexBytes <= targetBytes {
+		return 1
+	}
+	return int(math.Ceil(indexBytes / targetBytes))
+}
+
+// ShardsPerHeapGB returns the number of shards per GB of heap.
+func ShardsPerHeapGB(shards int, heapBytes float64) float64 {
+	if heapBytes <= 0 {
+		return 0
+	}
+	return float64(shards) / (heapBytes / (1024 * 1024 * 1024))
+}
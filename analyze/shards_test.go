@@ -0,0 +1,87 @@
+package analyze
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewDistribution(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		expected Distribution
+	}{
+		{"empty", nil, Distribution{}},
+		{"single", []float64{5}, Distribution{Count: 1, Min: 5, Median: 5, P90: 5, Max: 5}},
+		{"unsorted", []float64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5}, Distribution{Count: 10, Min: 1, Median: 5, P90: 9, Max: 10}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := NewDistribution(test.values); actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestObserveGrowth(t *testing.T) {
+	now := time.Date(2023, 7, 4, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2023, 7, d, 0, 0, 0, 0, time.UTC) }
+
+	growth := ObserveGrowth([]IndexSize{
+		{Index: "logs-3", Created: day(3), Primaries: 2, PrimaryBytes: 100},
+		{Index: "logs-1", Created: day(1), Primaries: 1, PrimaryBytes: 100},
+		{Index: "logs-2", Created: day(2), Primaries: 1, PrimaryBytes: 100},
+	}, now)
+
+	if growth.Indices != 3 {
+		t.Errorf("expected 3 indices, got %d", growth.Indices)
+	}
+	if growth.Interval != 24*time.Hour {
+		t.Errorf("expected an interval of a day, got %s", growth.Interval)
+	}
+	if growth.DailyBytes != 100 {
+		t.Errorf("expected 100 bytes per day, got %g", growth.DailyBytes)
+	}
+	if growth.Newest.Index != "logs-3" {
+		t.Errorf("expected logs-3 to be the newest, got %s", growth.Newest.Index)
+	}
+	if growth.IndexBytes() != 100 {
+		t.Errorf("expected 100 bytes per index, got %g", growth.IndexBytes())
+	}
+
+	single := ObserveGrowth([]IndexSize{{Index: "logs-1", Created: day(2), PrimaryBytes: 100}}, now)
+	if single.Interval != 48*time.Hour || single.DailyBytes != 50 {
+		t.Errorf("unexpected growth of a single index: %+v", single)
+	}
+}
+
+func TestRecommendPrimaries(t *testing.T) {
+	tests := []struct {
+		indexBytes  float64
+		targetBytes float64
+		expected    int
+	}{
+		{0, 40, 1},
+		{40, 40, 1},
+		{41, 40, 2},
+		{200, 40, 5},
+		{100, 0, 1},
+	}
+
+	for _, test := range tests {
+		if actual := RecommendPrimaries(test.indexBytes, test.targetBytes); actual != test.expected {
+			t.Errorf("RecommendPrimaries(%g, %g): expected %d, got %d", test.indexBytes, test.targetBytes, test.expected, actual)
+		}
+	}
+}
+
+func TestShardsPerHeapGB(t *testing.T) {
+	if actual := ShardsPerHeapGB(40, 2*1024*1024*1024); actual != 20 {
+		t.Errorf("expected 20, got %g", actual)
+	}
+	if actual := ShardsPerHeapGB(40, 0); actual != 0 {
+		t.Errorf("expected 0 without heap, got %g", actual)
+	}
+}
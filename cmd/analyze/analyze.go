@@ -0,0 +1,25 @@
+package analyze
+
+import (
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/spf13/cobra"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze the cluster for sizing and growth problems",
+	Long: utils.Trim(`
+Analyze the cluster for sizing and growth problems that are not visible in the
+output of the other commands.`),
+}
+
+func init() {
+	analyzeCmd.AddCommand(analyzeBalanceCmd)
+	analyzeCmd.AddCommand(analyzeDiskUsageCmd)
+	analyzeCmd.AddCommand(analyzeMappingsCmd)
+	analyzeCmd.AddCommand(analyzeShardsCmd)
+}
+
+func Cmd() *cobra.Command {
+	return analyzeCmd
+}
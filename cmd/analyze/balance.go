@@ -0,0 +1,258 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/analyze"
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/spf13/cobra"
+)
+
+var analyzeBalanceCmd = &cobra.Command{
+	Use:   "balance",
+	Short: "Report how the shards are balanced across nodes and node attributes",
+	Long: utils.Trim(`
+Report the number of shards, primaries and the store size held by every data node and
+by the nodes sharing every value of the node attributes, e.g. rack or hot/warm tiers.
+Nodes and attribute values whose shards or store per node are more than --threshold
+sample standard deviations away from the mean are flagged. The deviations of n groups can
+not exceed (n-1)/√n, just under √(n-1), so the default of 1 flags outliers among three or
+more groups but never between two.
+
+The indices with more primaries or shards on a node than an even spread over the data
+nodes of their tier would give are listed as skewed, with the node holding the most
+primaries. The tier of a node is the set of its data roles, and the tier of an index is
+the tier of the nodes holding its shards.
+
+Built-in attributes such as ml.*, xpack.* and transform.* are left out unless requested
+with --attribute.`),
+	Example: utils.TrimAndIndent(`
+# Report the balance of all shards.
+esctl analyze balance
+
+# Report the balance of the logs indices across racks.
+esctl analyze balance --index 'logs-*' --attribute rack`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		shards, err := es.GetShards(flagIndex)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve shards:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		nodes, err := es.GetNodes("")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve nodes:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		attributes, err := es.GetNodeAttributes()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve node attributes:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		var dataNodes []string
+		tiers := make(map[string]string)
+		for _, node := range nodes {
+			if tier := dataTier(node.NodeRole); tier != "" {
+				dataNodes = append(dataNodes, node.Name)
+				tiers[node.Name] = tier
+			}
+		}
+
+		placements := shardPlacements(shards)
+
+		byNode := func(node string) (string, bool) { return node, true }
+		fmt.Println("Nodes:")
+		printBalance("NODE", analyze.Balance(placements, byNode, dataNodes), false)
+
+		values := attributeValues(attributes)
+		for _, attr := range sortedKeys(values) {
+			byAttr := func(node string) (string, bool) {
+				value, ok := values[attr][node]
+				return value, ok
+			}
+			fmt.Printf("\nAttribute %s:\n", attr)
+			printBalance("VALUE", analyze.Balance(placements, byAttr, dataNodes), true)
+		}
+
+		fmt.Println()
+		printSkews(analyze.Skews(placements, tiers))
+	},
+}
+
+func init() {
+	analyzeBalanceCmd.Flags().StringVar(&flagAttribute, "attribute", "", "Node attribute to group by (default all custom attributes)")
+	analyzeBalanceCmd.Flags().StringVarP(&flagIndex, "index", "i", "", "Name or pattern of the indices")
+	analyzeBalanceCmd.Flags().Float64Var(&flagDeviations, "threshold", 1, "Flag the groups with shards or store per node more than this many standard deviations away from the mean")
+}
+
+// dataTier returns the data roles of the node, e.g. "h" for a node of the hot tier, or an
+// empty string for a node that holds no data.
+func dataTier(roles string) string {
+	var tier []rune
+	for _, role := range roles {
+		if strings.ContainsRune("dhwcfs", role) {
+			tier = append(tier, role)
+		}
+	}
+	return string(tier)
+}
+
+var builtinAttributePrefixes = []string{"ml.", "xpack.", "transform."}
+
+// shardPlacements returns the assigned shards. Relocating shards are counted on their
+// source node.
+func shardPlacements(shards []es.Shard) []analyze.ShardPlacement {
+	placements := make([]analyze.ShardPlacement, 0, len(shards))
+	for _, shard := range shards {
+		// Relocating shards are listed as "source -> ip id target".
+		fields := strings.Fields(shard.Node)
+		if len(fields) == 0 {
+			continue
+		}
+		store, _ := output.ParseDataSize(shard.Store)
+		placements = append(placements, analyze.ShardPlacement{
+			Index:   shard.Index,
+			Node:    fields[0],
+			Primary: shard.PriRep == constants.ShardPrimary,
+			Store:   store,
+		})
+	}
+	return placements
+}
+
+// attributeValues returns the values of the attributes by attribute and node name.
+func attributeValues(attributes []es.NodeAttribute) map[string]map[string]string {
+	values := make(map[string]map[string]string)
+	for _, attribute := range attributes {
+		if flagAttribute != "" && attribute.Attr != flagAttribute {
+			continue
+		}
+		if flagAttribute == "" && isBuiltinAttribute(attribute.Attr) {
+			continue
+		}
+		if values[attribute.Attr] == nil {
+			values[attribute.Attr] = make(map[string]string)
+		}
+		values[attribute.Attr][attribute.Node] = attribute.Value
+	}
+	return values
+}
+
+func isBuiltinAttribute(attr string) bool {
+	for _, prefix := range builtinAttributePrefixes {
+		if strings.HasPrefix(attr, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printBalance(header string, balances []analyze.GroupBalance, withNodes bool) {
+	columns := []output.ColumnDef{{Header: header, Type: output.Text}}
+	if withNodes {
+		columns = append(columns, output.ColumnDef{Header: "NODES", Type: output.Number})
+	}
+	columns = append(columns,
+		output.ColumnDef{Header: "SHARDS", Type: output.Number},
+		output.ColumnDef{Header: "PRIMARIES", Type: output.Number},
+		output.ColumnDef{Header: "STORE", Type: output.DataSize},
+		output.ColumnDef{Header: "STATUS", Type: output.Text},
+	)
+
+	shards := make([]float64, len(balances))
+	stores := make([]float64, len(balances))
+	for i, balance := range balances {
+		shards[i] = balance.ShardsPerNode()
+		stores[i] = balance.StorePerNode()
+	}
+	shardDeviations := analyze.Deviations(shards)
+	storeDeviations := analyze.Deviations(stores)
+
+	data := make([][]string, 0, len(balances))
+	for i, balance := range balances {
+		row := []string{balance.Group}
+		if withNodes {
+			row = append(row, strconv.Itoa(balance.Nodes))
+		}
+
+		var outliers []string
+		if math.Abs(shardDeviations[i]) > flagDeviations {
+			outliers = append(outliers, fmt.Sprintf("shards %+.1fsd", shardDeviations[i]))
+		}
+		if math.Abs(storeDeviations[i]) > flagDeviations {
+			outliers = append(outliers, fmt.Sprintf("store %+.1fsd", storeDeviations[i]))
+		}
+		status := "ok"
+		if len(outliers) > 0 {
+			status = strings.Join(outliers, ", ")
+		}
+
+		data = append(data, append(row,
+			strconv.Itoa(balance.Shards),
+			strconv.Itoa(balance.Primaries),
+			output.FormatDataSize(int64(balance.Store)),
+			status,
+		))
+	}
+
+	output.PrintTable(columns, data)
+}
+
+var skewColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "SHARDS", Type: output.Number},
+	{Header: "MAX-SHARDS-PER-NODE", Type: output.Number},
+	{Header: "IDEAL-SHARDS", Type: output.Number},
+	{Header: "PRIMARIES", Type: output.Number},
+	{Header: "TOP-NODE", Type: output.Text},
+	{Header: "TOP-NODE-PRIMARIES", Type: output.Number},
+	{Header: "IDEAL-PRIMARIES", Type: output.Number},
+}
+
+func printSkews(skews []analyze.IndexSkew) {
+	var data [][]string
+	for _, skew := range skews {
+		if !skew.Skewed() {
+			continue
+		}
+		data = append(data, []string{
+			skew.Index,
+			strconv.Itoa(skew.Shards),
+			strconv.Itoa(skew.MaxShards),
+			strconv.Itoa(skew.IdealShards()),
+			strconv.Itoa(skew.Primaries),
+			skew.TopNode,
+			strconv.Itoa(skew.TopPrimaries),
+			strconv.Itoa(skew.IdealPrimaries()),
+		})
+	}
+
+	if len(data) == 0 {
+		fmt.Println("No skewed indices.")
+		return
+	}
+
+	fmt.Println("Skewed indices:")
+	// The rows are kept in order so that the most skewed indices come first.
+	output.PrintTable(skewColumns, data)
+}
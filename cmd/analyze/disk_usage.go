@@ -0,0 +1,99 @@
+package analyze
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/spf13/cobra"
+)
+
+var analyzeDiskUsageCmd = &cobra.Command{
+	Use:   "disk-usage",
+	Short: "Report the disk usage of every field of an index",
+	Long: utils.Trim(`
+Report how many bytes every field of the index takes on disk, in total and in its
+inverted index, stored fields, doc values, points and norms, sorted by total.
+
+The analysis is done with the _disk_usage API, which reads every shard of the index
+and can take long and load the cluster, so it asks for a confirmation first unless
+--yes is given.`),
+	Example: utils.TrimAndIndent(`
+# Find the fields that cost the most bytes.
+esctl analyze disk-usage --index logs-2023.05.07
+
+# Skip the confirmation, e.g. in scripts.
+esctl analyze disk-usage --index logs-2023.05.07 --yes`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if flagIndex == "" {
+			fmt.Fprintln(os.Stderr, "--index is required")
+			os.Exit(constants.ExitCodeError)
+		}
+
+		question := fmt.Sprintf("Analyzing the disk usage of %s reads every shard and can load the cluster. Continue?", flagIndex)
+		if !flagYes && !utils.Confirm(question) {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			os.Exit(constants.ExitCodeError)
+		}
+
+		usage, err := es.AnalyzeDiskUsage(flagIndex)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to analyze disk usage:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		fmt.Printf("Store size: %s\n\n", output.FormatDataSize(usage.StoreSize))
+		printDiskUsage(usage.Fields)
+	},
+}
+
+func init() {
+	analyzeDiskUsageCmd.Flags().StringVarP(&flagIndex, "index", "i", "", "Name or pattern of the indices")
+	analyzeDiskUsageCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Do not ask for a confirmation")
+}
+
+var diskUsageColumns = []output.ColumnDef{
+	{Header: "FIELD", Type: output.Text},
+	{Header: "TOTAL", Type: output.DataSize},
+	{Header: "INVERTED-INDEX", Type: output.DataSize},
+	{Header: "STORED-FIELDS", Type: output.DataSize},
+	{Header: "DOC-VALUES", Type: output.DataSize},
+	{Header: "POINTS", Type: output.DataSize},
+	{Header: "NORMS", Type: output.DataSize},
+}
+
+func printDiskUsage(fields map[string]es.FieldDiskUsage) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	// Data sizes sort in ascending order, the largest fields are listed first instead.
+	sort.Slice(names, func(i, j int) bool {
+		left, right := fields[names[i]].Total, fields[names[j]].Total
+		if left != right {
+			return left > right
+		}
+		return names[i] < names[j]
+	})
+
+	data := make([][]string, 0, len(names))
+	for _, name := range names {
+		usage := fields[name]
+		data = append(data, []string{
+			name,
+			output.FormatDataSize(usage.Total),
+			output.FormatDataSize(usage.InvertedIndex),
+			output.FormatDataSize(usage.StoredFields),
+			output.FormatDataSize(usage.DocValues),
+			output.FormatDataSize(usage.Points),
+			output.FormatDataSize(usage.Norms),
+		})
+	}
+	output.PrintTable(diskUsageColumns, data)
+}
@@ -0,0 +1,11 @@
+package analyze
+
+var (
+	flagAttribute  string
+	flagDeviations float64
+	flagIndex      string
+	flagTargetSize string
+	flagThreshold  float64
+	flagTop        int
+	flagYes        bool
+)
@@ -0,0 +1,180 @@
+package analyze
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/analyze"
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/spf13/cobra"
+)
+
+var analyzeMappingsCmd = &cobra.Command{
+	Use:   "mappings",
+	Short: "Report mapping sizes against the field limits",
+	Long: utils.Trim(`
+Report, for every index, the total number of fields against
+index.mapping.total_fields.limit, the nesting depth, the number of nested objects,
+the dynamic setting and the object paths with the most fields. Indices using more
+than --threshold percent of the limit are flagged. The indices closest to the limit
+are listed first.
+
+When the pattern matches several indices, e.g. daily indices, the object paths
+whose number of fields grew the most from the oldest to the newest index are listed
+to find the source of a mapping explosion.`),
+	Example: utils.TrimAndIndent(`
+# Analyze the mappings of the logs indices.
+esctl analyze mappings --index 'logs-*'
+
+# Flag the indices using more than half of the limit.
+esctl analyze mappings --index 'logs-*' --threshold 50`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if flagTop < 0 {
+			fmt.Fprintln(os.Stderr, "Invalid top:", flagTop)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		mappings, err := es.GetMappings(flagIndex)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve mappings:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		settings, err := es.GetFlatIndexSettings(flagIndex)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve index settings:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		stats := make([]analyze.MappingStats, 0, len(mappings))
+		for index, indexMappings := range mappings {
+			limit := analyze.DefaultTotalFieldsLimit
+			if value, ok := settings[index]["index.mapping.total_fields.limit"]; ok {
+				if parsed, err := strconv.Atoi(fmt.Sprint(value)); err == nil {
+					limit = parsed
+				}
+			}
+			stats = append(stats, analyze.AnalyzeMappings(index, indexMappings, limit))
+		}
+
+		printMappingStats(stats)
+
+		if len(stats) > 1 {
+			sortByCreationDate(stats, settings)
+			fmt.Println()
+			printGrowth(stats[0], stats[len(stats)-1])
+		}
+	},
+}
+
+func init() {
+	analyzeMappingsCmd.Flags().StringVarP(&flagIndex, "index", "i", "", "Name or pattern of the indices")
+	analyzeMappingsCmd.Flags().Float64Var(&flagThreshold, "threshold", 80, "Flag indices using more than this percentage of the field limit")
+	analyzeMappingsCmd.Flags().IntVar(&flagTop, "top", 3, "Number of object paths to list")
+}
+
+var mappingColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "FIELDS", Type: output.Number},
+	{Header: "LIMIT", Type: output.Number},
+	{Header: "USAGE", Type: output.Percent},
+	{Header: "DEPTH", Type: output.Number},
+	{Header: "NESTED", Type: output.Number},
+	{Header: "DYNAMIC", Type: output.Text},
+	{Header: "TOP-OBJECTS", Type: output.Text},
+	{Header: "STATUS", Type: output.Text},
+}
+
+func printMappingStats(stats []analyze.MappingStats) {
+	// The indices closest to the limit are listed first, ordered by the exact usage rather
+	// than by the rounded column.
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Usage() != stats[j].Usage() {
+			return stats[i].Usage() > stats[j].Usage()
+		}
+		return stats[i].Index < stats[j].Index
+	})
+
+	data := make([][]string, 0, len(stats))
+	for _, s := range stats {
+		var objects []string
+		for _, object := range analyze.TopObjects(s.ObjectFields, flagTop) {
+			objects = append(objects, fmt.Sprintf("%s(%d)", object.Path, object.Fields))
+		}
+
+		data = append(data, []string{
+			s.Index,
+			strconv.Itoa(s.TotalFields),
+			strconv.Itoa(s.Limit),
+			fmt.Sprintf("%.1f%%", s.Usage()),
+			strconv.Itoa(s.Depth),
+			strconv.Itoa(s.NestedObjects),
+			s.Dynamic,
+			strings.Join(objects, ","),
+			mappingStatus(s),
+		})
+	}
+
+	output.PrintTable(mappingColumns, data)
+}
+
+func mappingStatus(s analyze.MappingStats) string {
+	switch {
+	case s.TotalFields >= s.Limit:
+		return "at limit"
+	case s.Usage() > flagThreshold:
+		return fmt.Sprintf("over %g%%", flagThreshold)
+	}
+	return "ok"
+}
+
+// sortByCreationDate sorts the statistics from the oldest to the newest index.
+func sortByCreationDate(stats []analyze.MappingStats, settings map[string]map[string]interface{}) {
+	created := func(index string) int64 {
+		date, _ := strconv.ParseInt(fmt.Sprint(settings[index]["index.creation_date"]), 10, 64)
+		return date
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		left, right := created(stats[i].Index), created(stats[j].Index)
+		if left != right {
+			return left < right
+		}
+		return stats[i].Index < stats[j].Index
+	})
+}
+
+var growthColumns = []output.ColumnDef{
+	{Header: "OBJECT", Type: output.Text},
+	{Header: "FROM", Type: output.Number},
+	{Header: "TO", Type: output.Number},
+	{Header: "GROWTH", Type: output.Number},
+}
+
+func printGrowth(oldest, newest analyze.MappingStats) {
+	growths := analyze.FastestGrowing(oldest, newest, flagTop)
+	if len(growths) == 0 {
+		fmt.Printf("No object path grew from %s to %s.\n", oldest.Index, newest.Index)
+		return
+	}
+
+	fmt.Printf("Fastest growing object paths from %s to %s:\n", oldest.Index, newest.Index)
+
+	data := make([][]string, 0, len(growths))
+	for _, growth := range growths {
+		data = append(data, []string{
+			growth.Path,
+			strconv.Itoa(growth.From),
+			strconv.Itoa(growth.To),
+			strconv.Itoa(growth.Growth()),
+		})
+	}
+	output.PrintTable(growthColumns, data)
+}
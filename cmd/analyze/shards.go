@@ -0,0 +1,222 @@
+package analyze
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/analyze"
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/spf13/cobra"
+)
+
+var analyzeShardsCmd = &cobra.Command{
+	Use:   "shards",
+	Short: "Recommend the number of primaries of an index pattern",
+	Long: utils.Trim(`
+Report the size and the document count distribution of the started primary shards of
+the indices matching the pattern, the number of shards per GB of heap on every node
+holding them, and a recommended number of primaries for the next index.
+
+The recommendation divides the primary data expected in an index, i.e. the observed
+daily growth of the pattern times the average interval between the creation of two
+indices, by --target-size.`),
+	Example: utils.TrimAndIndent(`
+# Size the primaries of the daily logs indices.
+esctl analyze shards --index 'logs-*'
+
+# Size the primaries for 30gb shards.
+esctl analyze shards --index 'logs-*' --target-size 30gb`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if flagIndex == "" {
+			fmt.Fprintln(os.Stderr, "--index is required")
+			os.Exit(constants.ExitCodeError)
+		}
+
+		targetBytes, err := output.ParseDataSize(flagTargetSize)
+		if err != nil || targetBytes <= 0 {
+			fmt.Fprintln(os.Stderr, "Invalid target size:", flagTargetSize)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		indices, err := es.GetIndices(flagIndex)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve indices:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+		if len(indices) == 0 {
+			fmt.Fprintln(os.Stderr, "No indices match", flagIndex)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		shards, err := es.GetShards("")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve shards:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		nodes, err := es.GetNodes("")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve nodes:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		matched := make(map[string]bool, len(indices))
+		for _, index := range indices {
+			matched[index.Index] = true
+		}
+
+		printShardDistribution(shards, matched)
+		fmt.Println()
+		printShardsPerHeap(shards, matched, nodes)
+		fmt.Println()
+		printRecommendation(indices, targetBytes)
+	},
+}
+
+func init() {
+	analyzeShardsCmd.Flags().StringVarP(&flagIndex, "index", "i", "", "Name or pattern of the indices")
+	analyzeShardsCmd.Flags().StringVar(&flagTargetSize, "target-size", output.FormatDataSize(analyze.DefaultTargetShardSize), "Target size of a primary shard")
+}
+
+var distributionColumns = []output.ColumnDef{
+	{Header: "METRIC", Type: output.Text},
+	{Header: "SHARDS", Type: output.Number},
+	{Header: "MIN", Type: output.Text},
+	{Header: "MEDIAN", Type: output.Text},
+	{Header: "P90", Type: output.Text},
+	{Header: "MAX", Type: output.Text},
+}
+
+func printShardDistribution(shards []es.Shard, matched map[string]bool) {
+	var sizes, docs []float64
+	for _, shard := range shards {
+		if !matched[shard.Index] || shard.PriRep != constants.ShardPrimary || shard.State != constants.ShardStateStarted {
+			continue
+		}
+		size, _ := output.ParseDataSize(shard.Store)
+		count, _ := strconv.ParseFloat(shard.Docs, 64)
+		sizes = append(sizes, size)
+		docs = append(docs, count)
+	}
+
+	formatSize := func(value float64) string { return output.FormatDataSize(int64(value)) }
+	formatCount := func(value float64) string { return strconv.FormatFloat(value, 'f', 0, 64) }
+
+	fmt.Println("Started primary shards:")
+	output.PrintTable(distributionColumns, [][]string{
+		distributionRow("STORE", analyze.NewDistribution(sizes), formatSize),
+		distributionRow("DOCS", analyze.NewDistribution(docs), formatCount),
+	})
+}
+
+func distributionRow(metric string, d analyze.Distribution, format func(float64) string) []string {
+	return []string{
+		metric,
+		strconv.Itoa(d.Count),
+		format(d.Min),
+		format(d.Median),
+		format(d.P90),
+		format(d.Max),
+	}
+}
+
+var shardsPerHeapColumns = []output.ColumnDef{
+	{Header: "NODE", Type: output.Text},
+	{Header: "PATTERN-SHARDS", Type: output.Number},
+	{Header: "TOTAL-SHARDS", Type: output.Number},
+	{Header: "HEAP-MAX", Type: output.DataSize},
+	{Header: "SHARDS-PER-GB-HEAP", Type: output.Number},
+	{Header: "STATUS", Type: output.Text},
+}
+
+func printShardsPerHeap(shards []es.Shard, matched map[string]bool, nodes []es.Node) {
+	total := make(map[string]int)
+	pattern := make(map[string]int)
+	for _, shard := range shards {
+		// Relocating shards are listed as "source -> ip id target" and counted on their
+		// source node.
+		fields := strings.Fields(shard.Node)
+		if len(fields) == 0 {
+			continue
+		}
+		total[fields[0]]++
+		if matched[shard.Index] {
+			pattern[fields[0]]++
+		}
+	}
+
+	data := make([][]string, 0, len(nodes))
+	for _, node := range nodes {
+		if pattern[node.Name] == 0 {
+			continue
+		}
+
+		heap, _ := output.ParseDataSize(node.HeapMax)
+		perGB := analyze.ShardsPerHeapGB(total[node.Name], heap)
+
+		status := "ok"
+		if perGB > analyze.MaxShardsPerHeapGB {
+			status = fmt.Sprintf("over %d", analyze.MaxShardsPerHeapGB)
+		}
+
+		data = append(data, []string{
+			node.Name,
+			strconv.Itoa(pattern[node.Name]),
+			strconv.Itoa(total[node.Name]),
+			node.HeapMax,
+			strconv.FormatFloat(perGB, 'f', 1, 64),
+			status,
+		})
+	}
+
+	fmt.Println("Nodes holding shards of the pattern:")
+	output.PrintTable(shardsPerHeapColumns, data, "SHARDS-PER-GB-HEAP", "NODE")
+}
+
+func printRecommendation(indices []es.Index, targetBytes float64) {
+	sizes := make([]analyze.IndexSize, 0, len(indices))
+	for _, index := range indices {
+		created, err := time.Parse(time.RFC3339Nano, index.CreationDate)
+		if err != nil {
+			continue
+		}
+		primaries, _ := strconv.Atoi(index.Pri)
+		bytes, _ := output.ParseDataSize(index.PriStoreSize)
+		sizes = append(sizes, analyze.IndexSize{
+			Index:        index.Index,
+			Created:      created,
+			Primaries:    primaries,
+			PrimaryBytes: bytes,
+		})
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].Index < sizes[j].Index })
+
+	growth := analyze.ObserveGrowth(sizes, time.Now())
+	if growth.Indices == 0 || growth.DailyBytes == 0 {
+		fmt.Println("Not enough data to observe the growth of the pattern.")
+		return
+	}
+
+	indexBytes := growth.IndexBytes()
+	fmt.Printf("Observed growth: %s per day over %d indices, a new index every %s.\n",
+		output.FormatDataSize(int64(growth.DailyBytes)), growth.Indices, formatInterval(growth.Interval))
+	fmt.Printf("Expected primary data per index: %s.\n", output.FormatDataSize(int64(indexBytes)))
+	fmt.Printf("Recommended primaries for %s shards: %d (%s has %d).\n",
+		output.FormatDataSize(int64(targetBytes)), analyze.RecommendPrimaries(indexBytes, targetBytes),
+		growth.Newest.Index, growth.Newest.Primaries)
+}
+
+func formatInterval(interval time.Duration) string {
+	if days := interval.Hours() / 24; days >= 1 {
+		return strconv.FormatFloat(days, 'f', 1, 64) + " days"
+	}
+	return interval.Round(time.Minute).String()
+}
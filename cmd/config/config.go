@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/shared"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -48,15 +50,7 @@ func runUseContext(cmd *cobra.Command, args []string) {
 	contextName := args[0]
 	config := ParseConfigFile()
 
-	contextExists := false
-	for _, context := range config.Contexts {
-		if context.Name == contextName {
-			contextExists = true
-			break
-		}
-	}
-
-	if !contextExists {
+	if _, ok := config.FindContext(contextName); !ok {
 		fmt.Printf("Error: No context found with the name '%s' in the configuration.\n", contextName)
 		os.Exit(1)
 	}
@@ -91,6 +85,9 @@ func runGetContexts(cmd *cobra.Command, args []string) {
 		if context.Password != "" {
 			fmt.Printf("  password: %s\n", context.Password)
 		}
+		if context.ReadOnly {
+			fmt.Printf("  read-only: %t\n", context.ReadOnly)
+		}
 	}
 }
 
@@ -106,6 +103,29 @@ type Context struct {
 	Port     int    `mapstructure:"port"`
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
+	ReadOnly bool   `mapstructure:"read-only"`
+}
+
+// Apply makes the context the one the requests to Elasticsearch are sent to.
+func (c Context) Apply() error {
+	if c.Host == "" {
+		return fmt.Errorf("'host' field is not specified in the configuration for the context '%s'", c.Name)
+	}
+
+	shared.ElasticsearchProtocol = c.Protocol
+	if shared.ElasticsearchProtocol == "" {
+		shared.ElasticsearchProtocol = constants.DefaultElasticsearchProtocol
+	}
+	shared.ElasticsearchPort = c.Port
+	if shared.ElasticsearchPort == 0 {
+		shared.ElasticsearchPort = constants.DefaultElasticsearchPort
+	}
+	shared.ElasticsearchUsername = c.Username
+	shared.ElasticsearchPassword = c.Password
+	shared.ReadOnly = c.ReadOnly
+	shared.ElasticsearchHost = c.Host
+
+	return nil
 }
 
 type Entity struct {
@@ -118,6 +138,16 @@ type Config struct {
 	Entities       map[string]Entity `mapstructure:"entities"`
 }
 
+// FindContext returns the context with the given name.
+func (c Config) FindContext(name string) (Context, bool) {
+	for _, context := range c.Contexts {
+		if context.Name == name {
+			return context, true
+		}
+	}
+	return Context{}, false
+}
+
 func ParseConfigFile() Config {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -2,10 +2,13 @@ package count
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/fehmicansaglam/esctl/cmd/utils"
 	"github.com/fehmicansaglam/esctl/es"
 	"github.com/fehmicansaglam/esctl/output"
 	"github.com/spf13/cobra"
@@ -14,7 +17,37 @@ import (
 var countCmd = &cobra.Command{
 	Use:   "count [--index index] [--group-by field]",
 	Short: "Count documents in an index or in all indices matching a pattern",
-	Args:  cobra.NoArgs,
+	Long: utils.Trim(`
+Count documents in an index or in all indices matching a pattern.
+
+Documents can be grouped on several levels. Repeated --group-by flags nest terms
+groupings in the given order, followed by the --histogram and --date-histogram
+groupings. Every level is printed as a separate column.
+
+Metrics are computed for every group with --metric type:field, where type is one
+of sum, avg, min, max or cardinality, or with --metric percentiles:field:50,95,99.
+Every metric value is printed as an extra column.
+
+Terms groupings return at most --size groups per level. The documents of the
+remaining groups are counted in an (other) row and a warning is printed on stderr
+when the results are truncated or approximate. Use --all-buckets to page through
+every group with exact counts instead.`),
+	Example: utils.TrimAndIndent(`
+# Count documents per category.
+esctl count --index articles --group-by category
+
+# Count errors per service per day.
+esctl count --index logs-* --term level:error --group-by service --date-histogram @timestamp:1d
+
+# Count documents per price range of 10.
+esctl count --index articles --histogram price:10
+
+# Count distinct users and the p99 latency per service.
+esctl count --index requests --group-by service --metric cardinality:user.id --metric percentiles:latency:50,99
+
+# Count documents of every author, including the ones without an author.
+esctl count --index articles --group-by author --all-buckets --missing "(none)"`),
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		handleCount()
 	},
@@ -24,35 +57,138 @@ func Cmd() *cobra.Command {
 	return countCmd
 }
 
+func parseGroupings() ([]es.Grouping, error) {
+	var groupings []es.Grouping
+	add := func(groupingType string, values []string) error {
+		for _, value := range values {
+			grouping, err := es.ParseGrouping(groupingType, value)
+			if err != nil {
+				return err
+			}
+			groupings = append(groupings, grouping)
+		}
+		return nil
+	}
+
+	if err := add(es.GroupingTerms, flagGroupBy); err != nil {
+		return nil, err
+	}
+	if err := add(es.GroupingHistogram, flagHistogram); err != nil {
+		return nil, err
+	}
+	if err := add(es.GroupingDateHistogram, flagDateHistogram); err != nil {
+		return nil, err
+	}
+	return groupings, nil
+}
+
+func parseMetrics() ([]es.Metric, error) {
+	metrics := make([]es.Metric, 0, len(flagMetric))
+	for _, value := range flagMetric {
+		metric, err := es.ParseMetric(value)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, nil
+}
+
+func formatMetric(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1e15 {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func printWarnings(counts map[string]es.GroupCount) {
+	indices := make([]string, 0, len(counts))
+	for index := range counts {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+
+	for _, index := range indices {
+		groupCount := counts[index]
+		if groupCount.OtherDocCount > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %d documents are in groups beyond --size and are counted in %s rows\n",
+				index, groupCount.OtherDocCount, es.OtherGroupKey)
+		}
+		if groupCount.ErrorUpperBound > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %s: group counts are approximate with an error of up to %d documents, use --all-buckets for exact counts\n",
+				index, groupCount.ErrorUpperBound)
+		}
+	}
+}
+
+func groupingColumnDef(grouping es.Grouping) output.ColumnDef {
+	header := strings.ToUpper(grouping.Field)
+	switch grouping.Type {
+	case es.GroupingHistogram:
+		return output.ColumnDef{Header: header, Type: output.Number}
+	case es.GroupingDateHistogram:
+		return output.ColumnDef{Header: header, Type: output.Date}
+	default:
+		return output.ColumnDef{Header: header, Type: output.Text}
+	}
+}
+
 func handleCount() {
+	groupings, err := parseGroupings()
+	if err != nil {
+		fmt.Printf("Invalid grouping: %v\n", err)
+		os.Exit(1)
+	}
+
+	metrics, err := parseMetrics()
+	if err != nil {
+		fmt.Printf("Invalid metric: %v\n", err)
+		os.Exit(1)
+	}
+
 	var counts map[string]es.GroupCount
-	var err error
 
-	counts, err = es.CountDocuments(flagIndex, flagTerm, flagExists, flagNested, flagGroupBy, flagSize, flagTimeout, flagRefresh)
+	counts, err = es.CountDocuments(flagIndex, flagTerm, flagExists, flagNested, groupings, metrics, flagSize, flagTimeout, flagMissing, flagAllBuckets, flagRefresh)
 	if err != nil {
 		fmt.Printf("Failed to get document counts: %v\n", err)
 		os.Exit(1)
 	}
 
-	columnDefs := []output.ColumnDef{
-		{Header: "INDEX", Type: output.Text},
-		{Header: strings.ToUpper(flagGroupBy), Type: output.Text},
-		{Header: "COUNT", Type: output.Number},
+	columnDefs := []output.ColumnDef{{Header: "INDEX", Type: output.Text}}
+	for _, grouping := range groupings {
+		columnDefs = append(columnDefs, groupingColumnDef(grouping))
+	}
+	columnDefs = append(columnDefs, output.ColumnDef{Header: "COUNT", Type: output.Number})
+
+	var metricLabels []string
+	for _, metric := range metrics {
+		for _, label := range metric.Labels() {
+			metricLabels = append(metricLabels, label)
+			columnDefs = append(columnDefs, output.ColumnDef{Header: strings.ToUpper(label), Type: output.Number})
+		}
 	}
 
 	data := [][]string{}
 
 	for index, groupCount := range counts {
-		for group, count := range groupCount {
-			rowData := map[string]string{
-				"INDEX":                      index,
-				strings.ToUpper(flagGroupBy): group,
-				"COUNT":                      strconv.Itoa(count),
+		for _, bucket := range groupCount.Buckets {
+			row := make([]string, 0, len(columnDefs))
+			row = append(row, index)
+			for level := range groupings {
+				key := ""
+				if level < len(bucket.Keys) {
+					key = bucket.Keys[level]
+				}
+				row = append(row, key)
 			}
-
-			row := make([]string, len(columnDefs))
-			for i, colDef := range columnDefs {
-				row[i] = rowData[colDef.Header]
+			row = append(row, strconv.Itoa(bucket.Count))
+			for _, label := range metricLabels {
+				value, ok := bucket.Metrics[label]
+				if !ok {
+					row = append(row, "")
+					continue
+				}
+				row = append(row, formatMetric(value))
 			}
 			data = append(data, row)
 		}
@@ -63,6 +199,8 @@ func handleCount() {
 	} else {
 		output.PrintTable(columnDefs, data, "INDEX")
 	}
+
+	printWarnings(counts)
 }
 
 func init() {
@@ -70,9 +208,14 @@ func init() {
 	countCmd.Flags().StringSliceVarP(&flagTerm, "term", "t", []string{}, "Term filters to apply")
 	countCmd.Flags().StringSliceVarP(&flagExists, "exists", "e", []string{}, "Exists filters to apply")
 	countCmd.Flags().StringArrayVar(&flagNested, "nested", []string{}, "Nested paths")
-	countCmd.Flags().StringVarP(&flagGroupBy, "group-by", "g", "", "Field to group the documents by")
+	countCmd.Flags().StringSliceVarP(&flagGroupBy, "group-by", "g", []string{}, "Field(s) to group the documents by, repeat to nest groupings")
+	countCmd.Flags().StringArrayVar(&flagHistogram, "histogram", []string{}, "Histogram grouping in field:interval format")
+	countCmd.Flags().StringArrayVar(&flagDateHistogram, "date-histogram", []string{}, "Date histogram grouping in field:interval format, e.g. @timestamp:1d")
+	countCmd.Flags().StringArrayVarP(&flagMetric, "metric", "m", []string{}, "Metric to compute per group: sum, avg, min, max or cardinality as type:field, or percentiles:field:50,95,99")
 	countCmd.Flags().StringSliceVarP(&flagSortBy, "sort-by", "s", []string{}, "Columns to sort by (comma-separated)")
 	countCmd.Flags().IntVar(&flagSize, "size", 0, "Set max results per group")
+	countCmd.Flags().StringVar(&flagMissing, "missing", "", "Group documents without a group field under this label")
+	countCmd.Flags().BoolVar(&flagAllBuckets, "all-buckets", false, "Page through all groups with a composite aggregation for exact counts")
 	countCmd.Flags().StringVar(&flagTimeout, "timeout", "", "Set timeout for group by query")
 	countCmd.Flags().BoolVar(&flagRefresh, "refresh", false, "Refresh index before counting documents")
 }
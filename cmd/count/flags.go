@@ -1,13 +1,18 @@
 package count
 
 var (
-	flagExists  []string
-	flagGroupBy string
-	flagIndex   string
-	flagNested  []string
-	flagSize    int
-	flagSortBy  []string
-	flagTerm    []string
-	flagTimeout string
-	flagRefresh bool
+	flagAllBuckets    bool
+	flagDateHistogram []string
+	flagExists        []string
+	flagGroupBy       []string
+	flagHistogram     []string
+	flagIndex         string
+	flagMetric        []string
+	flagMissing       string
+	flagNested        []string
+	flagSize          int
+	flagSortBy        []string
+	flagTerm          []string
+	flagTimeout       string
+	flagRefresh       bool
 )
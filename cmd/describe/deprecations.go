@@ -0,0 +1,117 @@
+package describe
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/es"
+)
+
+func handleDescribeDeprecations() {
+	deprecations, err := es.GetDeprecations(flagIndex)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to retrieve deprecations:", err)
+		os.Exit(1)
+	}
+
+	groups := deprecations.GroupByLevel()
+	if flagOutput == "markdown" {
+		fmt.Print(formatDeprecationsMarkdown(groups))
+		return
+	}
+
+	print(groups)
+}
+
+// formatDeprecationsMarkdown renders the deprecation issues as a markdown report with a
+// section per level, most severe first.
+func formatDeprecationsMarkdown(groups map[string]*es.DeprecationGroup) string {
+	var b strings.Builder
+	b.WriteString("# Deprecations\n")
+
+	if len(groups) == 0 {
+		b.WriteString("\nNo deprecation issues found.\n")
+		return b.String()
+	}
+
+	for _, level := range deprecationLevels(groups) {
+		group := groups[level]
+		fmt.Fprintf(&b, "\n## %s\n", levelTitle(level))
+
+		writeDeprecations(&b, "Cluster", group.Cluster)
+		writeDeprecations(&b, "Nodes", group.Nodes)
+		if len(group.Indices) > 0 {
+			indices := make([]string, 0, len(group.Indices))
+			for index := range group.Indices {
+				indices = append(indices, index)
+			}
+			sort.Strings(indices)
+
+			b.WriteString("\n### Indices\n")
+			for _, index := range indices {
+				writeDeprecations(&b, "#### "+index, group.Indices[index])
+			}
+		}
+		writeDeprecations(&b, "Machine Learning", group.ML)
+	}
+
+	return b.String()
+}
+
+// levelTitle capitalizes the level, naming the issues without a level "Unknown".
+func levelTitle(level string) string {
+	if level == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(level[:1]) + level[1:]
+}
+
+func writeDeprecations(b *strings.Builder, title string, deprecations []es.Deprecation) {
+	if len(deprecations) == 0 {
+		return
+	}
+
+	if !strings.HasPrefix(title, "#") {
+		title = "### " + title
+	}
+	fmt.Fprintf(b, "\n%s\n\n", title)
+
+	for _, deprecation := range deprecations {
+		fmt.Fprintf(b, "- **%s**", deprecation.Message)
+		if deprecation.URL != "" {
+			fmt.Fprintf(b, " ([docs](%s))", deprecation.URL)
+		}
+		if deprecation.ResolveDuringRollingUpgrade {
+			b.WriteString(" _can be resolved during a rolling upgrade_")
+		}
+		b.WriteString("\n")
+		if deprecation.Details != "" {
+			fmt.Fprintf(b, "  %s\n", deprecation.Details)
+		}
+	}
+}
+
+// deprecationLevels returns the levels present in groups, the known ones first in order of
+// severity.
+func deprecationLevels(groups map[string]*es.DeprecationGroup) []string {
+	var levels []string
+	known := make(map[string]bool)
+	for _, level := range es.DeprecationLevels {
+		known[level] = true
+		if _, ok := groups[level]; ok {
+			levels = append(levels, level)
+		}
+	}
+
+	var others []string
+	for level := range groups {
+		if !known[level] {
+			others = append(others, level)
+		}
+	}
+	sort.Strings(others)
+
+	return append(levels, others...)
+}
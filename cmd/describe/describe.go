@@ -3,8 +3,11 @@ package describe
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/fehmicansaglam/esctl/cmd/utils"
 	"github.com/fehmicansaglam/esctl/constants"
 	"github.com/fehmicansaglam/esctl/es"
 	"github.com/fehmicansaglam/esctl/output"
@@ -14,12 +17,16 @@ import (
 var describeCmd = &cobra.Command{
 	Short:     "Print detailed information about an entity",
 	Args:      cobra.RangeArgs(1, 2),
-	ValidArgs: []string{"cluster", "index", "node"},
+	ValidArgs: []string{"cluster", "deprecations", "index", "node"},
 	Run: func(cmd *cobra.Command, args []string) {
 		entity := args[0]
+		rejectUnsupportedFlags(cmd, entity)
+
 		switch entity {
 		case constants.EntityCluster:
 			handleDescribeCluster()
+		case constants.EntityDeprecations:
+			handleDescribeDeprecations()
 		case constants.EntityIndex:
 			if len(args) < 2 {
 				fmt.Println("Index name is required.")
@@ -45,7 +52,58 @@ func Cmd() *cobra.Command {
 	return describeCmd
 }
 
+// entityFlags lists the entities every entity-specific flag applies to.
+var entityFlags = map[string][]string{
+	"field-usage": {constants.EntityIndex},
+	"index":       {constants.EntityDeprecations},
+	"interval":    {constants.EntityCluster},
+	"sort-by":     {constants.EntityIndex},
+	"unused":      {constants.EntityIndex},
+	"watch":       {constants.EntityCluster},
+}
+
+// rejectUnsupportedFlags exits when a flag is given that the entity would ignore.
+func rejectUnsupportedFlags(cmd *cobra.Command, entity string) {
+	flags := make([]string, 0, len(entityFlags))
+	for flag := range entityFlags {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	for _, flag := range flags {
+		entities := entityFlags[flag]
+		if !cmd.Flags().Changed(flag) {
+			continue
+		}
+
+		supported := false
+		for _, e := range entities {
+			supported = supported || e == entity
+		}
+		if !supported {
+			fmt.Fprintf(os.Stderr, "Flag --%s is only supported for: %s\n", flag, strings.Join(entities, ", "))
+			os.Exit(constants.ExitCodeError)
+		}
+	}
+}
+
 func handleDescribeCluster() {
+	if flagWatch {
+		utils.Watch(flagInterval, func() (string, bool) {
+			cluster, err := es.GetCluster()
+			if err != nil {
+				return fmt.Sprint("Failed to retrieve cluster information: ", err), false
+			}
+
+			text, err := format(cluster)
+			if err != nil {
+				return fmt.Sprint("Failed to format cluster information: ", err), false
+			}
+			return text, false
+		})
+		return
+	}
+
 	cluster, err := es.GetCluster()
 	if err != nil {
 		fmt.Println("Failed to retrieve cluster information:", err)
@@ -56,6 +114,11 @@ func handleDescribeCluster() {
 }
 
 func handleDescribeIndex(index string) {
+	if flagFieldUsage || flagUnused {
+		handleDescribeFieldUsage(index)
+		return
+	}
+
 	shouldGetMappings := flagMappings || !flagSettings
 	shouldGetSettings := flagSettings || !flagMappings
 
@@ -90,11 +153,28 @@ func print(data interface{}) {
 	}
 }
 
+func format(data interface{}) (string, error) {
+	switch flagOutput {
+	case "json":
+		return output.FormatJson(data)
+	case "yaml":
+		return output.FormatYaml(data)
+	default:
+		return "", fmt.Errorf("unknown output type: %s", flagOutput)
+	}
+}
+
 func init() {
 	describeCmd.Use = fmt.Sprintf(`describe [%s] [NAME]`, strings.Join(describeCmd.ValidArgs, "|"))
 	describeCmd.Long = fmt.Sprintf("Print detailed information about the specified entity.\nAvailable entities: %s.", strings.Join(describeCmd.ValidArgs, ", "))
 
 	describeCmd.Flags().BoolVar(&flagMappings, "mappings", false, "If set, retrieve and print index mappings")
 	describeCmd.Flags().BoolVar(&flagSettings, "settings", false, "If set, retrieve and print index settings")
-	describeCmd.Flags().StringVarP(&flagOutput, "output", "o", "json", "Print output as json or yaml")
+	describeCmd.Flags().BoolVar(&flagFieldUsage, "field-usage", false, "If set, print a table of the usage of every index field")
+	describeCmd.Flags().BoolVar(&flagUnused, "unused", false, "If set, print the mapped index fields that were never accessed")
+	describeCmd.Flags().StringSliceVarP(&flagSortBy, "sort-by", "s", []string{}, "Columns to sort the field usage by (comma-separated)")
+	describeCmd.Flags().StringVarP(&flagOutput, "output", "o", "json", "Print output as json or yaml, or markdown for deprecations")
+	describeCmd.Flags().StringVar(&flagIndex, "index", "", "Index pattern to check for deprecations")
+	describeCmd.Flags().BoolVarP(&flagWatch, "watch", "w", false, "Redraw the cluster information in place and highlight changed lines")
+	describeCmd.Flags().DurationVar(&flagInterval, "interval", 2*time.Second, "Refresh interval in watch mode")
 }
@@ -0,0 +1,74 @@
+package describe
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+)
+
+var fieldUsageColumns = []output.ColumnDef{
+	{Header: "FIELD", Type: output.Text},
+	{Header: "ANY", Type: output.Number},
+	{Header: "INVERTED-INDEX", Type: output.Number},
+	{Header: "STORED-FIELDS", Type: output.Number},
+	{Header: "DOC-VALUES", Type: output.Number},
+	{Header: "POINTS", Type: output.Number},
+	{Header: "NORMS", Type: output.Number},
+	{Header: "TERM-VECTORS", Type: output.Number},
+	{Header: "KNN-VECTORS", Type: output.Number},
+}
+
+var unusedFieldColumns = []output.ColumnDef{
+	{Header: "FIELD", Type: output.Text},
+	{Header: "TYPE", Type: output.Text},
+}
+
+func handleDescribeFieldUsage(index string) {
+	usage, err := es.GetFieldUsage(index)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to retrieve field usage:", err)
+		os.Exit(1)
+	}
+
+	if flagUnused {
+		fields, err := es.GetFields(index)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve mappings:", err)
+			os.Exit(1)
+		}
+
+		unused := es.UnusedFields(fields, usage)
+		data := make([][]string, 0, len(unused))
+		for _, field := range unused {
+			data = append(data, []string{field.Path, field.Type})
+		}
+		output.PrintTable(unusedFieldColumns, data, sortBy("FIELD", "TYPE")...)
+		return
+	}
+
+	data := make([][]string, 0, len(usage))
+	for field, u := range usage {
+		data = append(data, []string{
+			field,
+			strconv.FormatInt(u.Any, 10),
+			strconv.FormatInt(u.InvertedIndex, 10),
+			strconv.FormatInt(u.StoredFields, 10),
+			strconv.FormatInt(u.DocValues, 10),
+			strconv.FormatInt(u.Points, 10),
+			strconv.FormatInt(u.Norms, 10),
+			strconv.FormatInt(u.TermVectors, 10),
+			strconv.FormatInt(u.KnnVectors, 10),
+		})
+	}
+	output.PrintTable(fieldUsageColumns, data, sortBy("FIELD")...)
+}
+
+func sortBy(defaultSortBy ...string) []string {
+	if len(flagSortBy) > 0 {
+		return flagSortBy
+	}
+	return defaultSortBy
+}
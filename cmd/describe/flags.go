@@ -1,7 +1,15 @@
 package describe
 
+import "time"
+
 var (
-	flagMappings bool
-	flagOutput   string
-	flagSettings bool
+	flagFieldUsage bool
+	flagIndex      string
+	flagInterval   time.Duration
+	flagMappings   bool
+	flagOutput     string
+	flagSettings   bool
+	flagSortBy     []string
+	flagUnused     bool
+	flagWatch      bool
 )
@@ -0,0 +1,111 @@
+package diagnostics
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/diagnostics"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/fehmicansaglam/esctl/shared"
+	"github.com/spf13/cobra"
+)
+
+var diagnosticsCmd = &cobra.Command{
+	Use:   "diagnostics",
+	Short: "Collect a diagnostics bundle of the cluster state",
+	Long: utils.Trim(`
+Collect the cluster health, state, stats and settings, the node info and stats, the
+_cat endpoints, tasks, pending tasks, templates, ILM explain and allocation explain
+into a gzipped tarball to attach to support cases and post-mortems.
+
+The APIs are requested concurrently. The bundle contains one JSON file per API and a
+manifest.json recording the endpoint, status, duration, size, checksum and error of
+every API, so that bundles can be compared later. APIs that fail are recorded in the
+manifest and do not stop the collection.
+
+Credentials such as passwords, secrets, tokens and keys are always redacted. Use
+--redact-ips to also replace IP addresses with placeholders that are consistent
+across the bundle.`),
+	Example: utils.TrimAndIndent(`
+# Collect a bundle.
+esctl diagnostics --out bundle.tar.gz
+
+# Collect a bundle without IP addresses.
+esctl diagnostics --out bundle.tar.gz --redact-ips`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := flagOut
+		if out == "" {
+			out = fmt.Sprintf("esctl-diagnostics-%s.tar.gz", time.Now().Format("20060102-150405"))
+		}
+
+		bundle := diagnostics.Collect(diagnostics.APIs, flagConcurrency, diagnostics.NewRedactor(flagRedactIPs))
+		printResults(bundle.Manifest.Results)
+
+		if err := bundle.Write(out); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to write bundle:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		failures := bundle.Failures()
+		fmt.Printf("\nWrote %s with %d APIs, %d failed.\n", out, len(bundle.Manifest.Results)-failures, failures)
+
+		if failures == len(bundle.Manifest.Results) {
+			os.Exit(constants.ExitCodeError)
+		}
+	},
+}
+
+func init() {
+	diagnosticsCmd.Flags().StringVar(&flagOut, "out", "", "Path of the bundle (default esctl-diagnostics-TIMESTAMP.tar.gz)")
+	diagnosticsCmd.Flags().BoolVar(&flagRedactIPs, "redact-ips", false, "Replace IP addresses with placeholders")
+	diagnosticsCmd.Flags().IntVar(&flagConcurrency, "concurrency", 4, "Number of APIs requested at the same time")
+}
+
+func Cmd() *cobra.Command {
+	return diagnosticsCmd
+}
+
+// UseBundle makes the read commands serve the responses recorded in the bundle instead
+// of calling the cluster.
+func UseBundle(path string) error {
+	bundle, err := diagnostics.Open(path)
+	if err != nil {
+		return err
+	}
+
+	es.SetTransport(diagnostics.NewTransport(bundle))
+	shared.ReadOnly = true
+	if shared.ElasticsearchHost == "" {
+		shared.ElasticsearchHost = "bundle"
+	}
+
+	return nil
+}
+
+var resultColumns = []output.ColumnDef{
+	{Header: "API", Type: output.Text},
+	{Header: "STATUS", Type: output.Number},
+	{Header: "DURATION-MS", Type: output.Number},
+	{Header: "SIZE", Type: output.Number},
+	{Header: "ERROR", Type: output.Text},
+}
+
+func printResults(results []diagnostics.Result) {
+	data := make([][]string, 0, len(results))
+	for _, result := range results {
+		data = append(data, []string{
+			result.Name,
+			strconv.Itoa(result.Status),
+			strconv.FormatInt(result.DurationMs, 10),
+			strconv.Itoa(result.Size),
+			result.Error,
+		})
+	}
+	output.PrintTable(resultColumns, data)
+}
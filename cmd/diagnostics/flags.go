@@ -0,0 +1,7 @@
+package diagnostics
+
+var (
+	flagConcurrency int
+	flagOut         string
+	flagRedactIPs   bool
+)
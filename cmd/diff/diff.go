@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/diagnostics"
+	"github.com/fehmicansaglam/esctl/diff"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const live = "live"
+
+const (
+	bold        = "\033[1m"
+	reset       = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff SNAPSHOT_A [SNAPSHOT_B|live]",
+	Short: "Show what changed in the cluster between two points in time",
+	Long: utils.Trim(`
+Compare two snapshots of the cluster, or a snapshot and the live cluster, and report
+the nodes that joined or left, the indices that were created, deleted or changed, the
+aliases that were swapped, the shards that moved and the cluster settings, index
+settings and mappings that changed.
+
+A snapshot is a bundle written by 'esctl diagnostics' or a directory of saved
+responses, as accepted by --from-bundle. The live cluster is used when the second
+snapshot is omitted.`),
+	Example: utils.TrimAndIndent(`
+# What changed since the bundle was collected.
+esctl diff before.tar.gz
+
+# What changed between two bundles.
+esctl diff before.tar.gz after.tar.gz -o json`),
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourceB := live
+		if len(args) == 2 {
+			sourceB = args[1]
+		}
+
+		// The live state is collected first, before the transport is replaced with a bundle.
+		var stateB *diff.State
+		if sourceB == live {
+			stateB = loadState(sourceB)
+		}
+		stateA := loadState(args[0])
+		if stateB == nil {
+			stateB = loadState(sourceB)
+		}
+
+		printChanges(diff.Compare(stateA, stateB))
+	},
+}
+
+func init() {
+	diffCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "text", "Print output as text or json")
+
+	diffCmd.AddCommand(diffIndexCmd)
+}
+
+func Cmd() *cobra.Command {
+	return diffCmd
+}
+
+func loadState(source string) *diff.State {
+	if source != live {
+		bundle, err := diagnostics.Open(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open snapshot %s: %v\n", source, err)
+			os.Exit(constants.ExitCodeError)
+		}
+		es.SetTransport(diagnostics.NewTransport(bundle))
+		defer es.SetTransport(http.DefaultTransport)
+	}
+
+	state, err := diff.Collect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load %s: %v\n", source, err)
+		os.Exit(constants.ExitCodeError)
+	}
+	return state
+}
+
+func printChanges(changes []diff.Change) {
+	switch flagOutput {
+	case "json":
+		output.PrintJson(changes)
+	case "text":
+		fmt.Print(formatChanges(changes, term.IsTerminal(int(os.Stdout.Fd()))))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown output type: %s\n", flagOutput)
+		os.Exit(constants.ExitCodeError)
+	}
+}
+
+// formatChanges renders the changes grouped by category, one line per change prefixed with
+// +, - or ~ for additions, removals and changes.
+func formatChanges(changes []diff.Change, color bool) string {
+	if len(changes) == 0 {
+		return "No changes.\n"
+	}
+
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + reset
+	}
+
+	var b strings.Builder
+	category := ""
+	for _, change := range changes {
+		if change.Category != category {
+			if category != "" {
+				b.WriteString("\n")
+			}
+			category = change.Category
+			b.WriteString(paint(bold, strings.ToUpper(category)) + "\n")
+		}
+
+		target := change.Name
+		if change.Field != "" {
+			target += " " + change.Field
+		}
+
+		switch change.Type {
+		case diff.Added:
+			line := "+ " + target
+			if change.New != "" {
+				line += ": " + change.New
+			}
+			b.WriteString(paint(colorGreen, line))
+		case diff.Removed:
+			line := "- " + target
+			if change.Old != "" {
+				line += ": " + change.Old
+			}
+			b.WriteString(paint(colorRed, line))
+		default:
+			b.WriteString(paint(colorYellow, fmt.Sprintf("~ %s: %s -> %s", target, change.Old, change.New)))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,7 @@
+package diff
+
+var (
+	flagContextB        string
+	flagIncludeVolatile bool
+	flagOutput          string
+)
@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fehmicansaglam/esctl/cmd/config"
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/diff"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/spf13/cobra"
+)
+
+var diffIndexCmd = &cobra.Command{
+	Use:   "index INDEX_A INDEX_B",
+	Short: "Compare the mappings and settings of two indices",
+	Long: utils.Trim(`
+Compare the flattened mappings and settings of two indices, one line per setting or
+mapping path that was added, removed or changed.
+
+Settings that differ between any two indices, such as index.uuid,
+index.creation_date and index.provided_name, are ignored unless
+--include-volatile is given. Use --context-b to read the second index from the
+cluster of another context, e.g. to compare staging with production.`),
+	Example: utils.TrimAndIndent(`
+# Why does orders-v3 behave differently from orders-v2?
+esctl diff index orders-v2 orders-v3
+
+# Compare the same index in staging and production.
+esctl diff index orders orders --context staging --context-b production`),
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		indexA := getIndexDetails(args[0])
+
+		if flagContextB != "" {
+			conf := config.ParseConfigFile()
+			context, ok := conf.FindContext(flagContextB)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "No context found with the name '%s' in the configuration.\n", flagContextB)
+				os.Exit(constants.ExitCodeError)
+			}
+			if err := context.Apply(); err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to use context:", err)
+				os.Exit(constants.ExitCodeError)
+			}
+		}
+		indexB := getIndexDetails(args[1])
+
+		printChanges(diff.CompareIndex(indexA, indexB, flagIncludeVolatile))
+	},
+}
+
+func init() {
+	diffIndexCmd.Flags().StringVar(&flagContextB, "context-b", "", "Context of the cluster to read the second index from")
+	diffIndexCmd.Flags().BoolVar(&flagIncludeVolatile, "include-volatile", false, "Also compare settings such as index.uuid and index.creation_date")
+}
+
+func getIndexDetails(index string) es.IndexDetails {
+	details, err := es.GetIndexDetails(index, true, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to retrieve index %s: %v\n", index, err)
+		os.Exit(constants.ExitCodeError)
+	}
+
+	if indexDetails, ok := details[index]; ok {
+		return indexDetails
+	}
+	// An alias resolves to the concrete index it points to.
+	if len(details) == 1 {
+		for _, indexDetails := range details {
+			return indexDetails
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Index %s must resolve to a single index, found %d.\n", index, len(details))
+	os.Exit(constants.ExitCodeError)
+	return es.IndexDetails{}
+}
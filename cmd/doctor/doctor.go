@@ -0,0 +1,163 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fehmicansaglam/esctl/cmd/config"
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/doctor"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the cluster for common problems",
+	Long: utils.Trim(`
+Check the cluster for common problems and print every finding with its severity,
+the affected entity and a hint on how to fix it.
+
+Built-in rules:
+  index-health         indices that are yellow or red
+  oversized-shards     primary shards larger than 50gb
+  small-shards         indices with many primary shards averaging less than 1gb
+  zero-replicas        indices without replicas
+  shards-per-node      nodes close to cluster.max_shards_per_node
+  unbalanced-shards    data nodes with far more or fewer shards than the average
+  disk-watermarks      nodes over the disk watermarks
+  high-heap            nodes with high heap usage
+  deprecated-settings  cluster and index settings that are deprecated or removed
+
+Custom rules are read from the rules section of esctl.yml and from the file given
+with --rules. A rule selects an entity (index, node, shard or alias), optionally
+filters its rows and asserts an expression over the columns shown by 'esctl get':
+
+  rules:
+    - name: logs-replicas
+      entity: index
+      filter: INDEX =~ "^logs-"
+      assert: REPLICAS >= 1
+      severity: critical
+      hint: Set index.number_of_replicas to 1.
+
+The exit code is 3 when there are critical findings and 1 on errors.`),
+	Example: utils.TrimAndIndent(`
+# Check the cluster.
+esctl doctor
+
+# Print the findings as JSON.
+esctl doctor -o json
+
+# Check the cluster with the rules of a team.
+esctl doctor --rules team-rules.yml`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config.ParseConfigFile()
+
+		rules, err := loadRules()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to load rules:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		snapshot, err := doctor.Collect()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to collect cluster state:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		findings := doctor.Run(snapshot, rules)
+		printFindings(findings)
+
+		if doctor.HasCritical(findings) {
+			os.Exit(constants.ExitCodeCritical)
+		}
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVarP(&flagOutput, "output", "o", "table", "Print output as table or json")
+	doctorCmd.Flags().StringVar(&flagRulesFile, "rules", "", "YAML file with custom rules")
+}
+
+func Cmd() *cobra.Command {
+	return doctorCmd
+}
+
+// loadRules returns the built-in rules followed by the custom rules of the config file
+// and the rules file.
+func loadRules() ([]doctor.Rule, error) {
+	var definitions []doctor.RuleDefinition
+	if err := viper.UnmarshalKey("rules", &definitions); err != nil {
+		return nil, err
+	}
+	if flagRulesFile != "" {
+		fileDefinitions, err := readRulesFile(flagRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, fileDefinitions...)
+	}
+
+	rules := append([]doctor.Rule{}, doctor.BuiltinRules...)
+	for _, definition := range definitions {
+		rule, err := doctor.NewCustomRule(definition)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func readRulesFile(path string) ([]doctor.RuleDefinition, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	var definitions []doctor.RuleDefinition
+	if err := v.UnmarshalKey("rules", &definitions); err != nil {
+		return nil, err
+	}
+
+	return definitions, nil
+}
+
+var findingColumns = []output.ColumnDef{
+	{Header: "SEVERITY", Type: output.Text},
+	{Header: "RULE", Type: output.Text},
+	{Header: "ENTITY", Type: output.Text},
+	{Header: "NAME", Type: output.Text},
+	{Header: "MESSAGE", Type: output.Text},
+	{Header: "HINT", Type: output.Text},
+}
+
+func printFindings(findings []doctor.Finding) {
+	switch flagOutput {
+	case "json":
+		output.PrintJson(findings)
+	case "table":
+		if len(findings) == 0 {
+			fmt.Println("No problems found.")
+			return
+		}
+
+		data := make([][]string, 0, len(findings))
+		for _, finding := range findings {
+			data = append(data, []string{
+				string(finding.Severity), finding.Rule, finding.Entity, finding.Name, finding.Message, finding.Hint,
+			})
+		}
+		output.PrintTable(findingColumns, data)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown output type: %s\n", flagOutput)
+		os.Exit(constants.ExitCodeError)
+	}
+}
@@ -0,0 +1,6 @@
+package doctor
+
+var (
+	flagOutput    string
+	flagRulesFile string
+)
@@ -0,0 +1,389 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/spf13/cobra"
+)
+
+const (
+	modePointInTime = "pit"
+	modeScroll      = "scroll"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export INDEX",
+	Short: "Export all matching documents of an index as NDJSON",
+	Long: utils.Trim(`
+Export every document of an index matching the given filters as NDJSON, one
+{"_id", "_index", "_source"} object per line.
+
+Documents are paged with a point in time and search_after. Clusters older than
+7.12 fall back to the scroll API. Progress is reported on stderr.
+
+When a checkpoint file is given, the position of every slice is saved after each
+page and an interrupted export can be resumed by running the same command again.
+The checkpoint file is removed once the export completes. With --max-docs the
+point in time is kept open so the next run continues where this one stopped;
+in scroll mode the documents trimmed from the last page are skipped.`),
+	Example: utils.TrimAndIndent(`
+# Export all documents of an index to stdout.
+esctl export articles
+
+# Export the published articles to a file.
+esctl export articles --term "status:published" --file articles.ndjson
+
+# Export with 4 parallel slices and a resumable checkpoint.
+esctl export articles --slices 4 --checkpoint articles.checkpoint --file articles.ndjson`),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handleExport(cmd, args[0])
+	},
+}
+
+func Cmd() *cobra.Command {
+	return exportCmd
+}
+
+type sliceState struct {
+	SearchAfter []json.RawMessage `json:"search_after,omitempty"`
+	ScrollID    string            `json:"scroll_id,omitempty"`
+	Exported    int               `json:"exported"`
+	Done        bool              `json:"done"`
+}
+
+type checkpoint struct {
+	Index  string       `json:"index"`
+	Mode   string       `json:"mode"`
+	PitID  string       `json:"pit_id,omitempty"`
+	Slices []sliceState `json:"slices"`
+}
+
+type exportedHit struct {
+	ID     string          `json:"_id"`
+	Index  string          `json:"_index"`
+	Source json.RawMessage `json:"_source"`
+}
+
+type exporter struct {
+	mu         sync.Mutex
+	index      string
+	writer     *bufio.Writer
+	state      checkpoint
+	totals     map[int]int
+	exported   int
+	limitHit   bool
+	checkpoint string
+}
+
+func readCheckpoint(path string) (*checkpoint, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state checkpoint
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+func writeCheckpoint(path string, state checkpoint) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func newCheckpoint(index string) (checkpoint, error) {
+	state := checkpoint{
+		Index:  index,
+		Mode:   modeScroll,
+		Slices: make([]sliceState, flagSlices),
+	}
+
+	if !flagScroll {
+		supported, err := es.SupportsPointInTime()
+		if err != nil {
+			return checkpoint{}, err
+		}
+		if supported {
+			state.Mode = modePointInTime
+		} else {
+			fmt.Fprintln(os.Stderr, "Point in time is not supported by the cluster, falling back to scroll.")
+		}
+	}
+
+	if state.Mode == modePointInTime {
+		pitID, err := es.OpenPointInTime(index, flagKeepAlive)
+		if err != nil {
+			return checkpoint{}, fmt.Errorf("failed to open point in time: %w", err)
+		}
+		state.PitID = pitID
+	}
+
+	return state, nil
+}
+
+func handleExport(cmd *cobra.Command, index string) {
+	if flagSlices < 1 {
+		fmt.Fprintln(os.Stderr, "--slices must be at least 1")
+		os.Exit(1)
+	}
+
+	var state *checkpoint
+	if flagCheckpoint != "" {
+		var err error
+		state, err = readCheckpoint(flagCheckpoint)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to read checkpoint:", err)
+			os.Exit(1)
+		}
+		if state != nil && state.Index != index {
+			fmt.Fprintf(os.Stderr, "Checkpoint %s belongs to index %s\n", flagCheckpoint, state.Index)
+			os.Exit(1)
+		}
+		// The documents are split into the slices of the checkpoint, so a resumed export
+		// can not change their number.
+		if state != nil && cmd.Flags().Changed("slices") && flagSlices != len(state.Slices) {
+			fmt.Fprintf(os.Stderr, "Checkpoint %s was started with %d slices\n", flagCheckpoint, len(state.Slices))
+			os.Exit(1)
+		}
+	}
+
+	resuming := state != nil
+	if !resuming {
+		created, err := newCheckpoint(index)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to start export:", err)
+			os.Exit(1)
+		}
+		state = &created
+	} else {
+		fmt.Fprintf(os.Stderr, "Resuming export from %s\n", flagCheckpoint)
+	}
+
+	var out io.Writer = os.Stdout
+	if flagFile != "" {
+		mode := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if resuming {
+			mode = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		file, err := os.OpenFile(flagFile, mode, 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to open output file:", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	e := &exporter{
+		index:      index,
+		writer:     bufio.NewWriter(out),
+		state:      *state,
+		totals:     make(map[int]int),
+		checkpoint: flagCheckpoint,
+	}
+
+	err := e.run()
+	e.writer.Flush()
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		if e.checkpoint == "" {
+			e.release()
+		}
+		fmt.Fprintln(os.Stderr, "Failed to export documents:", err)
+		os.Exit(1)
+	}
+
+	if e.completed() {
+		e.release()
+		if e.checkpoint != "" {
+			os.Remove(e.checkpoint)
+		}
+	} else if e.checkpoint == "" {
+		e.release()
+	}
+}
+
+func (e *exporter) run() error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(e.state.Slices))
+
+	for slice := range e.state.Slices {
+		wg.Add(1)
+		go func(slice int) {
+			defer wg.Done()
+			errs[slice] = e.exportSlice(slice)
+		}(slice)
+	}
+	wg.Wait()
+
+	for slice, err := range errs {
+		if err != nil {
+			return fmt.Errorf("slice %d: %w", slice, err)
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportSlice(slice int) error {
+	slices := len(e.state.Slices)
+
+	for {
+		e.mu.Lock()
+		state := e.state.Slices[slice]
+		pitID := e.state.PitID
+		stop := state.Done || e.limitHit
+		e.mu.Unlock()
+
+		if stop {
+			return nil
+		}
+
+		var response es.SearchHitsResponse
+		var err error
+		switch {
+		case e.state.Mode == modePointInTime:
+			response, err = es.SearchPointInTime(pitID, flagKeepAlive, flagTerm, flagNested, slice, slices, flagBatchSize, state.SearchAfter)
+		case state.ScrollID == "":
+			response, err = es.StartScroll(e.index, flagKeepAlive, flagTerm, flagNested, slice, slices, flagBatchSize)
+		default:
+			response, err = es.ContinueScroll(state.ScrollID, flagKeepAlive)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := e.write(slice, state.SearchAfter == nil && state.ScrollID == "", response); err != nil {
+			return err
+		}
+	}
+}
+
+// write appends a page of hits to the output and saves the position of the slice.
+func (e *exporter) write(slice int, firstPage bool, response es.SearchHitsResponse) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	hits := response.Hits.Hits
+	trimmed := false
+	if flagMaxDocs > 0 && e.exported+len(hits) >= flagMaxDocs {
+		trimmed = e.exported+len(hits) > flagMaxDocs
+		hits = hits[:flagMaxDocs-e.exported]
+		e.limitHit = true
+	}
+
+	for _, hit := range hits {
+		line, err := json.Marshal(exportedHit{ID: hit.ID, Index: hit.Index, Source: hit.Source})
+		if err != nil {
+			return err
+		}
+		e.writer.Write(line)
+		e.writer.WriteByte('\n')
+	}
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+
+	state := &e.state.Slices[slice]
+	if e.state.Mode == modePointInTime && len(hits) > 0 {
+		state.SearchAfter = hits[len(hits)-1].Sort
+	}
+	if response.PitID != "" {
+		e.state.PitID = response.PitID
+	}
+	if response.ScrollID != "" {
+		state.ScrollID = response.ScrollID
+	}
+	state.Exported += len(hits)
+	state.Done = !trimmed && len(response.Hits.Hits) < flagBatchSize
+	e.exported += len(hits)
+
+	if firstPage {
+		e.totals[slice] = response.Hits.Total.Value
+	}
+	e.printProgress()
+
+	if e.checkpoint != "" {
+		return writeCheckpoint(e.checkpoint, e.state)
+	}
+	return nil
+}
+
+func (e *exporter) printProgress() {
+	exported := 0
+	for _, state := range e.state.Slices {
+		exported += state.Exported
+	}
+
+	if len(e.totals) == len(e.state.Slices) {
+		total := 0
+		for _, sliceTotal := range e.totals {
+			total += sliceTotal
+		}
+		fmt.Fprintf(os.Stderr, "\rExported %d/%d documents", exported, total)
+	} else {
+		fmt.Fprintf(os.Stderr, "\rExported %d documents", exported)
+	}
+}
+
+func (e *exporter) completed() bool {
+	for _, state := range e.state.Slices {
+		if !state.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// release frees the point in time or the scroll contexts held on the cluster.
+func (e *exporter) release() {
+	if e.state.Mode == modePointInTime {
+		if err := es.ClosePointInTime(e.state.PitID); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to close point in time:", err)
+		}
+		return
+	}
+
+	for _, state := range e.state.Slices {
+		if state.ScrollID == "" {
+			continue
+		}
+		if err := es.ClearScroll(state.ScrollID); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to clear scroll:", err)
+		}
+	}
+}
+
+func init() {
+	exportCmd.Flags().StringArrayVarP(&flagTerm, "term", "t", []string{}, "Term filter(s)")
+	exportCmd.Flags().StringArrayVar(&flagNested, "nested", []string{}, "Nested path(s)")
+	exportCmd.Flags().StringVarP(&flagFile, "file", "f", "", "File to write the documents to (defaults to stdout)")
+	exportCmd.Flags().IntVar(&flagBatchSize, "batch-size", 1000, "Number of documents fetched per request")
+	exportCmd.Flags().IntVar(&flagMaxDocs, "max-docs", 0, "Maximum number of documents to export in this run (0 for all)")
+	exportCmd.Flags().IntVar(&flagSlices, "slices", 1, "Number of slices exported in parallel, taken from the checkpoint when resuming")
+	exportCmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "", "File to save and resume the export position from")
+	exportCmd.Flags().StringVar(&flagKeepAlive, "keep-alive", "5m", "How long the point in time or scroll is kept alive between requests")
+	exportCmd.Flags().BoolVar(&flagScroll, "scroll", false, "Use the scroll API even if point in time is supported")
+}
@@ -0,0 +1,166 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fehmicansaglam/esctl/es"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.checkpoint")
+
+	state, err := readCheckpoint(path)
+	if err != nil || state != nil {
+		t.Fatalf("Expected no checkpoint for a missing file, got %+v, %v", state, err)
+	}
+
+	written := checkpoint{
+		Index: "articles",
+		Mode:  modePointInTime,
+		PitID: "p1",
+		Slices: []sliceState{
+			{SearchAfter: []json.RawMessage{json.RawMessage(`42`)}, Exported: 100},
+			{Exported: 50, Done: true},
+		},
+	}
+	if err := writeCheckpoint(path, written); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary file to be renamed, got %v", err)
+	}
+
+	read, err := readCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*read, written) {
+		t.Errorf("Expected %+v, got %+v", written, *read)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readCheckpoint(path); err == nil {
+		t.Error("Expected error for an invalid checkpoint, but got nil")
+	}
+}
+
+func page(total int, ids ...string) es.SearchHitsResponse {
+	var response es.SearchHitsResponse
+	response.Hits.Total.Value = total
+	for i, id := range ids {
+		response.Hits.Hits = append(response.Hits.Hits, es.SearchHit{
+			Index:  "articles",
+			ID:     id,
+			Source: json.RawMessage(`{}`),
+			Sort:   []json.RawMessage{json.RawMessage(strings.Repeat("1", i+1))},
+		})
+	}
+	return response
+}
+
+func TestExporterWrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		maxDocs       int
+		exported      int
+		response      es.SearchHitsResponse
+		expectedLines int
+		expectedState sliceState
+		expectedLimit bool
+	}{
+		{
+			name:          "full page",
+			mode:          modePointInTime,
+			response:      page(10, "a", "b", "c"),
+			expectedLines: 3,
+			expectedState: sliceState{SearchAfter: []json.RawMessage{json.RawMessage(`111`)}, Exported: 3},
+		},
+		{
+			name:          "last page",
+			mode:          modePointInTime,
+			response:      page(10, "a", "b"),
+			expectedLines: 2,
+			expectedState: sliceState{SearchAfter: []json.RawMessage{json.RawMessage(`11`)}, Exported: 2, Done: true},
+		},
+		{
+			name:          "trimmed by max docs",
+			mode:          modePointInTime,
+			maxDocs:       5,
+			exported:      3,
+			response:      page(10, "a", "b", "c"),
+			expectedLines: 2,
+			expectedState: sliceState{SearchAfter: []json.RawMessage{json.RawMessage(`11`)}, Exported: 2},
+			expectedLimit: true,
+		},
+		{
+			name:          "scroll keeps the scroll id",
+			mode:          modeScroll,
+			response:      func() es.SearchHitsResponse { r := page(10, "a", "b", "c"); r.ScrollID = "s1"; return r }(),
+			expectedLines: 3,
+			expectedState: sliceState{ScrollID: "s1", Exported: 3},
+		},
+	}
+
+	defer func(batchSize, maxDocs int) { flagBatchSize, flagMaxDocs = batchSize, maxDocs }(flagBatchSize, flagMaxDocs)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flagBatchSize, flagMaxDocs = 3, test.maxDocs
+
+			var out bytes.Buffer
+			path := filepath.Join(t.TempDir(), "export.checkpoint")
+			e := &exporter{
+				index:      "articles",
+				writer:     bufio.NewWriter(&out),
+				state:      checkpoint{Index: "articles", Mode: test.mode, Slices: make([]sliceState, 2)},
+				totals:     make(map[int]int),
+				exported:   test.exported,
+				checkpoint: path,
+			}
+
+			if err := e.write(1, true, test.response); err != nil {
+				t.Fatal(err)
+			}
+
+			if lines := strings.Count(out.String(), "\n"); lines != test.expectedLines {
+				t.Errorf("Expected %d lines, got %d", test.expectedLines, lines)
+			}
+			if !reflect.DeepEqual(e.state.Slices[1], test.expectedState) {
+				t.Errorf("Expected slice state %+v, got %+v", test.expectedState, e.state.Slices[1])
+			}
+			if e.limitHit != test.expectedLimit {
+				t.Errorf("Expected limit hit %v, got %v", test.expectedLimit, e.limitHit)
+			}
+			if e.totals[1] != 10 {
+				t.Errorf("Expected the total of the first page to be recorded, got %d", e.totals[1])
+			}
+
+			saved, err := readCheckpoint(path)
+			if err != nil || saved == nil || !reflect.DeepEqual(saved.Slices[1], test.expectedState) {
+				t.Errorf("Expected the checkpoint to hold the slice state, got %+v, %v", saved, err)
+			}
+		})
+	}
+}
+
+func TestExporterCompleted(t *testing.T) {
+	e := &exporter{state: checkpoint{Slices: []sliceState{{Done: true}, {Done: false}}}}
+	if e.completed() {
+		t.Error("Expected the export not to be completed while a slice is not done")
+	}
+
+	e.state.Slices[1].Done = true
+	if !e.completed() {
+		t.Error("Expected the export to be completed when all slices are done")
+	}
+}
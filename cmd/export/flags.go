@@ -0,0 +1,13 @@
+package export
+
+var (
+	flagBatchSize  int
+	flagCheckpoint string
+	flagFile       string
+	flagKeepAlive  string
+	flagMaxDocs    int
+	flagNested     []string
+	flagScroll     bool
+	flagSlices     int
+	flagTerm       []string
+)
@@ -0,0 +1,247 @@
+package forecast
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/forecast"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/fehmicansaglam/esctl/shared"
+	"github.com/spf13/cobra"
+)
+
+var forecastDiskCmd = &cobra.Command{
+	Use:   "disk",
+	Short: "Estimate the time until the nodes reach the disk watermarks",
+	Long: utils.Trim(`
+Record a sample of the disk usage of every node and the store size of every index in a
+local state file, fit a linear trend to the samples of the cluster and estimate the time
+until every node and the cluster as a whole reach the low, high and flood stage disk
+watermarks. The cluster grows by the sum of the trends of its current nodes, so nodes
+joining or leaving are not mistaken for growth. The indices growing the fastest are
+listed as well.
+
+Run the command periodically, e.g. from cron, to build up the samples; at least two
+samples at different times are needed for a forecast. Samples older than --retention
+are dropped. The samples are kept per cluster address in --state-file.`),
+	Example: utils.TrimAndIndent(`
+# Record a sample and forecast the disk usage.
+esctl forecast disk
+
+# Forecast from the recorded samples without recording a new one.
+esctl forecast disk --no-record`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if flagTop < 0 {
+			fmt.Fprintln(os.Stderr, "Invalid top:", flagTop)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		path := flagStateFile
+		if path == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to get the home directory:", err)
+				os.Exit(constants.ExitCodeError)
+			}
+			path = filepath.Join(home, ".config", "esctl-forecast.json")
+		}
+
+		state, err := forecast.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to read state file:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		cluster := fmt.Sprintf("%s://%s:%d", shared.ElasticsearchProtocol, shared.ElasticsearchHost, shared.ElasticsearchPort)
+
+		// Bundles are snapshots of the past, so they are never recorded as samples of now.
+		if !flagNoRecord && shared.BundlePath == "" {
+			sample, err := collectSample()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to collect sample:", err)
+				os.Exit(constants.ExitCodeError)
+			}
+
+			state.Record(cluster, sample, flagRetention)
+			if err := state.Save(path); err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to write state file:", err)
+				os.Exit(constants.ExitCodeError)
+			}
+		}
+
+		samples := state.Clusters[cluster]
+		if len(samples) < 2 {
+			fmt.Printf("%d sample(s) recorded for %s in %s; at least two are needed for a forecast.\n", len(samples), cluster, path)
+			return
+		}
+
+		settings, err := es.GetFlatClusterSettings()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to retrieve cluster settings:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		watermarks := make(map[string]string)
+		for _, watermark := range forecast.Watermarks {
+			if value, ok := settings.Get("cluster.routing.allocation.disk.watermark." + watermark.Name); ok {
+				watermarks[watermark.Name] = value
+			}
+		}
+
+		nodes, total, err := forecast.Disk(samples, watermarks)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to forecast disk usage:", err)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		first, last := samples[0].Time, samples[len(samples)-1].Time
+		fmt.Printf("Forecast from %d samples between %s and %s.\n\n", len(samples), first.Format(time.RFC3339), last.Format(time.RFC3339))
+		printDiskForecasts(append(nodes, total))
+
+		if growths := forecast.FastestGrowingIndices(samples, flagTop); len(growths) > 0 {
+			fmt.Println()
+			printIndexGrowths(growths)
+		}
+	},
+}
+
+func init() {
+	forecastDiskCmd.Flags().BoolVar(&flagNoRecord, "no-record", false, "Forecast from the recorded samples without recording a new one")
+	forecastDiskCmd.Flags().DurationVar(&flagRetention, "retention", 30*24*time.Hour, "Drop the samples older than this")
+	forecastDiskCmd.Flags().StringVar(&flagStateFile, "state-file", "", "Path of the state file (default ~/.config/esctl-forecast.json)")
+	forecastDiskCmd.Flags().IntVar(&flagTop, "top", 5, "Number of the fastest growing indices to list")
+}
+
+func collectSample() (forecast.Sample, error) {
+	// The sizes are requested in bytes, the rounded sizes of large disks hide the daily
+	// growth.
+	disks, err := es.GetNodeDisks()
+	if err != nil {
+		return forecast.Sample{}, fmt.Errorf("failed to retrieve nodes: %w", err)
+	}
+
+	stores, err := es.GetIndexStores()
+	if err != nil {
+		return forecast.Sample{}, fmt.Errorf("failed to retrieve indices: %w", err)
+	}
+
+	sample := forecast.Sample{
+		Time:    time.Now().UTC(),
+		Nodes:   make(map[string]forecast.NodeDisk, len(disks)),
+		Indices: make(map[string]float64, len(stores)),
+	}
+
+	for _, disk := range disks {
+		// Nodes without data paths, e.g. dedicated masters on some setups, report no disk.
+		if disk.DiskTotal == "" {
+			continue
+		}
+		used, err := strconv.ParseFloat(disk.DiskUsed, 64)
+		if err != nil {
+			return forecast.Sample{}, fmt.Errorf("invalid disk usage of node %s: %w", disk.Name, err)
+		}
+		total, err := strconv.ParseFloat(disk.DiskTotal, 64)
+		if err != nil {
+			return forecast.Sample{}, fmt.Errorf("invalid disk size of node %s: %w", disk.Name, err)
+		}
+		sample.Nodes[disk.Name] = forecast.NodeDisk{Used: used, Total: total}
+	}
+
+	for _, store := range stores {
+		// Closed indices report no store size.
+		if store.StoreSize == "" {
+			continue
+		}
+		size, err := strconv.ParseFloat(store.StoreSize, 64)
+		if err != nil {
+			return forecast.Sample{}, fmt.Errorf("invalid store size of index %s: %w", store.Index, err)
+		}
+		sample.Indices[store.Index] = size
+	}
+
+	return sample, nil
+}
+
+var diskForecastColumns = []output.ColumnDef{
+	{Header: "NODE", Type: output.Text},
+	{Header: "DISK-USED", Type: output.DataSize},
+	{Header: "DISK-TOTAL", Type: output.DataSize},
+	{Header: "GROWTH-PER-DAY", Type: output.Text},
+	{Header: "LOW", Type: output.Text},
+	{Header: "HIGH", Type: output.Text},
+	{Header: "FLOOD-STAGE", Type: output.Text},
+}
+
+func printDiskForecasts(forecasts []forecast.Forecast) {
+	data := make([][]string, 0, len(forecasts))
+	for _, f := range forecasts {
+		growth := "-"
+		if f.Fitted {
+			growth = formatGrowth(f.Trend.PerDay)
+		}
+
+		row := []string{
+			f.Name,
+			output.FormatDataSize(int64(f.Used)),
+			output.FormatDataSize(int64(f.Total)),
+			growth,
+		}
+		for _, watermark := range forecast.Watermarks {
+			row = append(row, formatETA(f.ETAs[watermark.Name], f.Fitted))
+		}
+		data = append(data, row)
+	}
+
+	// The rows are kept in order so that the cluster stays last.
+	output.PrintTable(diskForecastColumns, data)
+}
+
+var indexGrowthColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "STORE", Type: output.DataSize},
+	{Header: "GROWTH-PER-DAY", Type: output.Text},
+}
+
+func printIndexGrowths(growths []forecast.IndexGrowth) {
+	fmt.Println("Fastest growing indices:")
+
+	data := make([][]string, 0, len(growths))
+	for _, growth := range growths {
+		data = append(data, []string{
+			growth.Index,
+			output.FormatDataSize(int64(growth.Store)),
+			formatGrowth(growth.Trend.PerDay),
+		})
+	}
+	output.PrintTable(indexGrowthColumns, data)
+}
+
+func formatGrowth(perDay float64) string {
+	if perDay < 0 {
+		return "-" + output.FormatDataSize(int64(-perDay))
+	}
+	return output.FormatDataSize(int64(perDay))
+}
+
+func formatETA(eta forecast.ETA, fitted bool) string {
+	switch {
+	case eta.Reached:
+		return "reached"
+	case !fitted:
+		return "-"
+	case eta.Never:
+		return "never"
+	case eta.Beyond:
+		return fmt.Sprintf("> %d years", forecast.MaxETADays/365)
+	case eta.In >= 24*time.Hour:
+		return strconv.FormatFloat(eta.In.Hours()/24, 'f', 1, 64) + " days"
+	}
+	return eta.In.Round(time.Minute).String()
+}
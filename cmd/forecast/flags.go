@@ -0,0 +1,10 @@
+package forecast
+
+import "time"
+
+var (
+	flagNoRecord  bool
+	flagRetention time.Duration
+	flagStateFile string
+	flagTop       int
+)
@@ -0,0 +1,21 @@
+package forecast
+
+import (
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/spf13/cobra"
+)
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Forecast the resource usage of the cluster",
+	Long: utils.Trim(`
+Forecast the resource usage of the cluster from samples recorded by repeated runs.`),
+}
+
+func init() {
+	forecastCmd.AddCommand(forecastDiskCmd)
+}
+
+func Cmd() *cobra.Command {
+	return forecastCmd
+}
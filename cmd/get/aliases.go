@@ -2,12 +2,11 @@ package get
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/fehmicansaglam/esctl/cmd/config"
 	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/entities"
 	"github.com/fehmicansaglam/esctl/es"
-	"github.com/fehmicansaglam/esctl/output"
 	"github.com/spf13/cobra"
 )
 
@@ -34,38 +33,20 @@ func init() {
 	getAliasesCmd.Flags().StringVarP(&flagIndex, "index", "i", "", "Name of the index")
 }
 
-var aliasColumns = []output.ColumnDef{
-	{Header: "ALIAS", Type: output.Text},
-	{Header: "INDEX", Type: output.Text},
+func handleAliasLogic(conf config.Config) {
+	printTable(conf, aliasesTable)
 }
 
-func handleAliasLogic(conf config.Config) {
+func aliasesTable(conf config.Config) (table, error) {
 	aliases, err := es.GetAliases(flagIndex)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to retrieve aliases:", err)
-		os.Exit(1)
+		return table{}, fmt.Errorf("failed to retrieve aliases: %w", err)
 	}
 
-	columnDefs, err := getColumnDefs(conf, "alias", aliasColumns)
+	columnDefs, err := getColumnDefs(conf, "alias", entities.AliasColumns)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to get column definitions:", err)
-		os.Exit(1)
-	}
-
-	data := [][]string{}
-
-	for alias, index := range aliases {
-		rowData := map[string]string{
-			"ALIAS": alias,
-			"INDEX": index,
-		}
-
-		row := make([]string, len(columnDefs))
-		for i, colDef := range columnDefs {
-			row[i] = rowData[colDef.Header]
-		}
-		data = append(data, row)
+		return table{}, fmt.Errorf("failed to get column definitions: %w", err)
 	}
 
-	output.PrintTable(columnDefs, data, flagSortBy...)
+	return buildTable(columnDefs, entities.AliasRows(aliases), "ALIAS"), nil
 }
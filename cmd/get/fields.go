@@ -0,0 +1,141 @@
+package get
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/cmd/config"
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/spf13/cobra"
+)
+
+var getFieldsCmd = &cobra.Command{
+	Use:   "fields",
+	Short: "Get the fields of index mappings",
+	Long: utils.Trim(`
+The 'fields' command flattens the mappings of the indices into one row per field,
+with the dot path of the field, its type, the path of the nested object it belongs
+to, whether it is indexed and has doc values, its analyzer and its multi-fields.
+
+With --conflicts, only the fields mapped with different types across the indices
+are listed, with the indices mapping the field with every type.`),
+	Example: utils.TrimAndIndent(`
+# List the fields of an index.
+esctl get fields --index my_index
+
+# List the keyword fields under user.
+esctl get fields --index my_index --type keyword --pattern 'user.*'
+
+# List the fields mapped with different types across indices.
+esctl get fields --index 'logs-*' --conflicts`),
+	Run: func(cmd *cobra.Command, args []string) {
+		conf := config.ParseConfigFile()
+		if flagConflicts {
+			printTable(conf, fieldConflictsTable)
+			return
+		}
+		printTable(conf, fieldsTable)
+	},
+}
+
+func init() {
+	getFieldsCmd.Flags().StringVarP(&flagIndex, "index", "i", "", "Name or pattern of the indices")
+	getFieldsCmd.Flags().StringVar(&flagType, "type", "", "Filter fields by type")
+	getFieldsCmd.Flags().StringVar(&flagPattern, "pattern", "", "Filter fields by a wildcard pattern on their path")
+	getFieldsCmd.Flags().BoolVar(&flagConflicts, "conflicts", false, "List the fields mapped with different types across indices")
+}
+
+var fieldColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "FIELD", Type: output.Text},
+	{Header: "TYPE", Type: output.Text},
+	{Header: "NESTED-PATH", Type: output.Text},
+	{Header: "INDEXED", Type: output.Text},
+	{Header: "DOC-VALUES", Type: output.Text},
+	{Header: "ANALYZER", Type: output.Text},
+	{Header: "MULTI-FIELDS", Type: output.Text},
+}
+
+var fieldConflictColumns = []output.ColumnDef{
+	{Header: "FIELD", Type: output.Text},
+	{Header: "TYPE", Type: output.Text},
+	{Header: "COUNT", Type: output.Number},
+	{Header: "INDICES", Type: output.Text},
+}
+
+func includeField(field es.Field) bool {
+	if flagType != "" && field.Type != flagType {
+		return false
+	}
+	if flagPattern != "" {
+		if matched, _ := path.Match(flagPattern, field.Path); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldsTable(conf config.Config) (table, error) {
+	fields, err := es.GetFields(flagIndex)
+	if err != nil {
+		return table{}, fmt.Errorf("failed to retrieve mappings: %w", err)
+	}
+
+	columnDefs, err := getColumnDefs(conf, "field", fieldColumns)
+	if err != nil {
+		return table{}, fmt.Errorf("failed to get column definitions: %w", err)
+	}
+
+	rows := []map[string]string{}
+	for index, indexFields := range fields {
+		for _, field := range indexFields {
+			if includeField(field) {
+				rows = append(rows, map[string]string{
+					"INDEX":        index,
+					"FIELD":        field.Path,
+					"TYPE":         field.Type,
+					"NESTED-PATH":  field.NestedPath,
+					"INDEXED":      strconv.FormatBool(field.Indexed),
+					"DOC-VALUES":   strconv.FormatBool(field.DocValues),
+					"ANALYZER":     field.Analyzer,
+					"MULTI-FIELDS": strings.Join(field.MultiFields, ","),
+				})
+			}
+		}
+	}
+
+	return buildTable(columnDefs, rows, "INDEX", "FIELD"), nil
+}
+
+func fieldConflictsTable(conf config.Config) (table, error) {
+	fields, err := es.GetFields(flagIndex)
+	if err != nil {
+		return table{}, fmt.Errorf("failed to retrieve mappings: %w", err)
+	}
+
+	columnDefs, err := getColumnDefs(conf, "field-conflict", fieldConflictColumns)
+	if err != nil {
+		return table{}, fmt.Errorf("failed to get column definitions: %w", err)
+	}
+
+	rows := []map[string]string{}
+	for fieldPath, indicesByType := range es.FieldTypeConflicts(fields) {
+		for fieldType, indices := range indicesByType {
+			if !includeField(es.Field{Path: fieldPath, Type: fieldType}) {
+				continue
+			}
+			rows = append(rows, map[string]string{
+				"FIELD":   fieldPath,
+				"TYPE":    fieldType,
+				"COUNT":   strconv.Itoa(len(indices)),
+				"INDICES": strings.Join(indices, ","),
+			})
+		}
+	}
+
+	return buildTable(columnDefs, rows, "FIELD", "TYPE"), nil
+}
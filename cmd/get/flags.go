@@ -1,16 +1,26 @@
 package get
 
+import "time"
+
 var (
-	flagActions      []string
-	flagColumns      []string
-	flagIndex        string
-	flagInitializing bool
-	flagNode         string
-	flagPrimary      bool
-	flagRelocating   bool
-	flagReplica      bool
-	flagShard        int
-	flagSortBy       []string
-	flagStarted      bool
-	flagUnassigned   bool
+	flagActions        []string
+	flagColumns        []string
+	flagConflicts      bool
+	flagIndex          string
+	flagInitializing   bool
+	flagInterval       time.Duration
+	flagNode           string
+	flagPattern        string
+	flagPrimary        bool
+	flagRate           bool
+	flagRelocating     bool
+	flagReplica        bool
+	flagSampleInterval time.Duration
+	flagShard          int
+	flagSortBy         []string
+	flagStarted        bool
+	flagType           string
+	flagUnassigned     bool
+	flagUntilEmpty     bool
+	flagWatch          bool
 )
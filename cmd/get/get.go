@@ -2,7 +2,9 @@ package get
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/fehmicansaglam/esctl/cmd/config"
 	"github.com/fehmicansaglam/esctl/cmd/utils"
@@ -10,6 +12,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
+type table struct {
+	columnDefs []output.ColumnDef
+	data       [][]string
+	sortBy     []string
+}
+
+type tableBuilder func(conf config.Config) (table, error)
+
 var getCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get Elasticsearch entities",
@@ -21,6 +31,7 @@ Available Entities:
   - indices: List all indices in the Elasticsearch cluster.
   - shards: List detailed information about shards, including their sizes and placement.
   - aliases: List all aliases in the Elasticsearch cluster.
+  - fields: List the fields of index mappings.
   - tasks: List all tasks in the Elasticsearch cluster.`),
 	Example: utils.TrimAndIndent(`
 #Retrieve a list of all nodes in the Elasticsearch cluster.
@@ -41,18 +52,28 @@ esctl get shards --started --relocating
 #Retrieve all aliases.
 esctl get aliases
 
+#Retrieve the fields of an index.
+esctl get fields --index my_index
+
 #Retrieve tasks filtered by actions using wildcard patterns.
 esctl get tasks --actions 'index*' --actions '*search*'
 
 #Retrieve all tasks.
-esctl get tasks`),
+esctl get tasks
+
+#Watch unassigned shards until none are left.
+esctl get shards --unassigned --watch --until-empty`),
 }
 
 func init() {
 	getCmd.PersistentFlags().StringSliceVarP(&flagSortBy, "sort-by", "s", []string{}, "Columns to sort by (comma-separated)")
 	getCmd.PersistentFlags().StringSliceVarP(&flagColumns, "columns", "c", []string{}, "Columns to display (comma-separated) or 'all'")
+	getCmd.PersistentFlags().BoolVarP(&flagWatch, "watch", "w", false, "Redraw the table in place and highlight changed rows")
+	getCmd.PersistentFlags().DurationVar(&flagInterval, "interval", 2*time.Second, "Refresh interval in watch mode")
+	getCmd.PersistentFlags().BoolVar(&flagUntilEmpty, "until-empty", false, "Stop watching when the table has no rows")
 
 	getCmd.AddCommand(getAliasesCmd)
+	getCmd.AddCommand(getFieldsCmd)
 	getCmd.AddCommand(getIndicesCmd)
 	getCmd.AddCommand(getNodesCmd)
 	getCmd.AddCommand(getShardsCmd)
@@ -97,3 +118,47 @@ func getColumnDefs(conf config.Config, entity string, defaultColumns []output.Co
 		return buildColumnDefs(entityConfig.Columns, defaultColumns)
 	}
 }
+
+// buildTable arranges the row data into the given columns. The table is sorted by
+// the --sort-by columns or by the default ones.
+func buildTable(columnDefs []output.ColumnDef, rows []map[string]string, defaultSortBy ...string) table {
+	data := make([][]string, 0, len(rows))
+	for _, rowData := range rows {
+		row := make([]string, len(columnDefs))
+		for i, colDef := range columnDefs {
+			row[i] = rowData[colDef.Header]
+		}
+		data = append(data, row)
+	}
+
+	sortBy := defaultSortBy
+	if len(flagSortBy) > 0 {
+		sortBy = flagSortBy
+	}
+
+	return table{columnDefs: columnDefs, data: data, sortBy: sortBy}
+}
+
+func printTable(conf config.Config, build tableBuilder) {
+	if flagWatch {
+		utils.Watch(flagInterval, func() (string, bool) {
+			t, err := build(conf)
+			if err != nil {
+				return err.Error(), false
+			}
+
+			var rendered strings.Builder
+			output.RenderTable(&rendered, t.columnDefs, t.data, t.sortBy...)
+			return rendered.String(), flagUntilEmpty && len(t.data) == 0
+		})
+		return
+	}
+
+	t, err := build(conf)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	output.PrintTable(t.columnDefs, t.data, t.sortBy...)
+}
@@ -2,12 +2,11 @@ package get
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/fehmicansaglam/esctl/cmd/config"
 	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/entities"
 	"github.com/fehmicansaglam/esctl/es"
-	"github.com/fehmicansaglam/esctl/output"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +22,9 @@ var getIndicesCmd = &cobra.Command{
 
 	# Retrieve indices for a specific index.
 	esctl get indices --index my_index
+
+	# Show the busiest indices by indexing rate over 10 seconds.
+	esctl get indices --rate --sample-interval 10s --sort-by indexing-rate
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
 		conf := config.ParseConfigFile()
@@ -32,62 +34,39 @@ var getIndicesCmd = &cobra.Command{
 
 func init() {
 	getIndicesCmd.Flags().StringVarP(&flagIndex, "index", "i", "", "Name of the index")
+	addRateFlags(getIndicesCmd)
 }
 
-var indexColumns = []output.ColumnDef{
-	{Header: "INDEX", Type: output.Text},
-	{Header: "UUID", Type: output.Text},
-	{Header: "HEALTH", Type: output.Text},
-	{Header: "STATUS", Type: output.Text},
-	{Header: "SHARDS", Type: output.Number},
-	{Header: "REPLICAS", Type: output.Number},
-	{Header: "DOCS-COUNT", Type: output.Number},
-	{Header: "DOCS-DELETED", Type: output.Number},
-	{Header: "CREATION-DATE", Type: output.Date},
-	{Header: "STORE-SIZE", Type: output.DataSize},
-	{Header: "PRI-STORE-SIZE", Type: output.DataSize},
-}
+var indexColumns = appendRateColumns(entities.IndexColumns)
 
 func handleIndicesLogic(conf config.Config) {
+	printTable(conf, indicesTable)
+}
+
+func indicesTable(conf config.Config) (table, error) {
 	indices, err := es.GetIndices(flagIndex)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to retrieve indices:", err)
-		os.Exit(1)
+		return table{}, fmt.Errorf("failed to retrieve indices: %w", err)
 	}
 
-	columnDefs, err := getColumnDefs(conf, "index", indexColumns)
+	rates, err := sampleRates(func() (map[string]es.OperationStats, error) {
+		return es.GetIndexStats(flagIndex)
+	})
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to get column definitions:", err)
-		os.Exit(1)
+		return table{}, err
 	}
 
-	data := [][]string{}
+	columnDefs, err := getColumnDefs(conf, "index", indexColumns)
+	if err != nil {
+		return table{}, fmt.Errorf("failed to get column definitions: %w", err)
+	}
 
+	rows := make([]map[string]string, 0, len(indices))
 	for _, index := range indices {
-		rowData := map[string]string{
-			"INDEX":          index.Index,
-			"UUID":           index.UUID,
-			"HEALTH":         index.Health,
-			"STATUS":         index.Status,
-			"SHARDS":         index.Pri,
-			"REPLICAS":       index.Rep,
-			"DOCS-COUNT":     index.DocsCount,
-			"DOCS-DELETED":   index.DocsDeleted,
-			"CREATION-DATE":  index.CreationDate,
-			"STORE-SIZE":     index.StoreSize,
-			"PRI-STORE-SIZE": index.PriStoreSize,
-		}
-
-		row := make([]string, len(columnDefs))
-		for i, colDef := range columnDefs {
-			row[i] = rowData[colDef.Header]
-		}
-		data = append(data, row)
+		row := entities.IndexRow(index)
+		addRates(row, rates, index.Index)
+		rows = append(rows, row)
 	}
 
-	if len(flagSortBy) > 0 {
-		output.PrintTable(columnDefs, data, flagSortBy...)
-	} else {
-		output.PrintTable(columnDefs, data, "INDEX")
-	}
+	return buildTable(withRateColumns(columnDefs), rows, "INDEX"), nil
 }
@@ -0,0 +1,75 @@
+package get
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"github.com/spf13/cobra"
+)
+
+var rateColumns = []output.ColumnDef{
+	{Header: "INDEXING-RATE", Type: output.Number},
+	{Header: "SEARCH-RATE", Type: output.Number},
+	{Header: "QUERY-LATENCY", Type: output.Number},
+	{Header: "MERGE-TIME", Type: output.Number},
+	{Header: "REFRESH-TIME", Type: output.Number},
+}
+
+// appendRateColumns returns a copy of the columns of an entity followed by the rate columns.
+func appendRateColumns(columnDefs []output.ColumnDef) []output.ColumnDef {
+	return append(append([]output.ColumnDef{}, columnDefs...), rateColumns...)
+}
+
+func addRateFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&flagRate, "rate", false, "Sample the stats twice and show operation rates")
+	cmd.Flags().DurationVar(&flagSampleInterval, "sample-interval", 5*time.Second, "Time between the two samples in rate mode")
+}
+
+// sampleRates returns the operation rates in rate mode and nil otherwise.
+func sampleRates(sample func() (map[string]es.OperationStats, error)) (map[string]es.OperationRates, error) {
+	if !flagRate {
+		return nil, nil
+	}
+
+	rates, err := es.SampleRates(sample, flagSampleInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample stats: %w", err)
+	}
+
+	return rates, nil
+}
+
+// withRateColumns appends the rate columns missing from the selected columns in rate mode.
+func withRateColumns(columnDefs []output.ColumnDef) []output.ColumnDef {
+	if !flagRate {
+		return columnDefs
+	}
+
+	selected := make(map[string]bool, len(columnDefs))
+	for _, columnDef := range columnDefs {
+		selected[columnDef.Header] = true
+	}
+
+	for _, columnDef := range rateColumns {
+		if !selected[columnDef.Header] {
+			columnDefs = append(columnDefs, columnDef)
+		}
+	}
+
+	return columnDefs
+}
+
+func addRates(row map[string]string, rates map[string]es.OperationRates, key string) {
+	rate, ok := rates[key]
+	if !ok {
+		return
+	}
+
+	row["INDEXING-RATE"] = fmt.Sprintf("%.1f", rate.IndexingRate)
+	row["SEARCH-RATE"] = fmt.Sprintf("%.1f", rate.SearchRate)
+	row["QUERY-LATENCY"] = fmt.Sprintf("%.1f", rate.QueryLatency)
+	row["MERGE-TIME"] = fmt.Sprintf("%.1f", rate.MergeTimeRate)
+	row["REFRESH-TIME"] = fmt.Sprintf("%.1f", rate.RefreshRate)
+}
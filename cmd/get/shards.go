@@ -8,8 +8,8 @@ import (
 	"github.com/fehmicansaglam/esctl/cmd/config"
 	"github.com/fehmicansaglam/esctl/cmd/utils"
 	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/entities"
 	"github.com/fehmicansaglam/esctl/es"
-	"github.com/fehmicansaglam/esctl/output"
 	"github.com/spf13/cobra"
 )
 
@@ -93,77 +93,28 @@ func includeShardByNode(shard es.Shard) bool {
 	return shard.Node == flagNode
 }
 
-func humanizePriRep(priRep string) string {
-	switch priRep {
-	case constants.ShardPrimary:
-		return "primary"
-	case constants.ShardReplica:
-		return "replica"
-	default:
-		return priRep
-	}
-}
-
-var shardColumns = []output.ColumnDef{
-	{Header: "INDEX", Type: output.Text},
-	{Header: "SHARD", Type: output.Number},
-	{Header: "PRI-REP", Type: output.Text},
-	{Header: "STATE", Type: output.Text},
-	{Header: "DOCS", Type: output.Number},
-	{Header: "STORE", Type: output.DataSize},
-	{Header: "IP", Type: output.Text},
-	{Header: "NODE", Type: output.Text},
-	{Header: "NODE-ID", Type: output.Text},
-	{Header: "UNASSIGNED-REASON", Type: output.Text},
-	{Header: "UNASSIGNED-AT", Type: output.Date},
-	{Header: "SEGMENTS-COUNT", Type: output.Number},
+func handleShardLogic(conf config.Config) {
+	printTable(conf, shardsTable)
 }
 
-func handleShardLogic(conf config.Config) {
+func shardsTable(conf config.Config) (table, error) {
 	shards, err := es.GetShards(flagIndex)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to retrieve shards:", err)
-		os.Exit(1)
+		return table{}, fmt.Errorf("failed to retrieve shards: %w", err)
 	}
 
-	columnDefs, err := getColumnDefs(conf, "shard", shardColumns)
+	columnDefs, err := getColumnDefs(conf, "shard", entities.ShardColumns)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to get column definitions:", err)
-		os.Exit(1)
+		return table{}, fmt.Errorf("failed to get column definitions: %w", err)
 	}
 
-	data := [][]string{}
-
+	rows := []map[string]string{}
 	for _, shard := range shards {
 		if includeShardByState(shard) && includeShardByNumber(shard) &&
 			includeShardByPriRep(shard) && includeShardByNode(shard) {
-
-			rowData := map[string]string{
-				"INDEX":             shard.Index,
-				"SHARD":             shard.Shard,
-				"PRI-REP":           humanizePriRep(shard.PriRep),
-				"STATE":             shard.State,
-				"DOCS":              shard.Docs,
-				"STORE":             shard.Store,
-				"IP":                shard.IP,
-				"NODE":              shard.Node,
-				"NODE-ID":           shard.ID,
-				"UNASSIGNED-REASON": shard.UnassignedReason,
-				"UNASSIGNED-AT":     shard.UnassignedAt,
-				"SEGMENTS-COUNT":    shard.SegmentsCount,
-			}
-
-			row := make([]string, len(columnDefs))
-			for i, colDef := range columnDefs {
-				row[i] = rowData[colDef.Header]
-			}
-			data = append(data, row)
+			rows = append(rows, entities.ShardRow(shard))
 		}
 	}
 
-	if len(flagSortBy) > 0 {
-		output.PrintTable(columnDefs, data, flagSortBy...)
-	} else {
-		output.PrintTable(columnDefs, data, "INDEX", "SHARD", "PRI-REP")
-	}
+	return buildTable(columnDefs, rows, "INDEX", "SHARD", "PRI-REP"), nil
 }
@@ -2,7 +2,6 @@ package get
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/fehmicansaglam/esctl/cmd/config"
 	"github.com/fehmicansaglam/esctl/es"
@@ -34,42 +33,37 @@ var taskColumns = []output.ColumnDef{
 }
 
 func handleTaskLogic(config config.Config) {
+	printTable(config, tasksTable)
+}
+
+func taskRow(task es.Task) map[string]string {
+	return map[string]string{
+		"NODE":         task.Node,
+		"ID":           fmt.Sprintf("%d", task.ID),
+		"ACTION":       task.Action,
+		"DESCRIPTION":  task.Description,
+		"START-TIME":   fmt.Sprintf("%d", task.StartTimeInMillis),
+		"RUNNING-TIME": fmt.Sprintf("%d", task.RunningTimeInNanos),
+	}
+}
+
+func tasksTable(config config.Config) (table, error) {
 	tasksResponse, err := es.GetTasks(flagActions)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to retrieve tasks:", err)
-		os.Exit(1)
+		return table{}, fmt.Errorf("failed to retrieve tasks: %w", err)
 	}
 
 	columnDefs, err := getColumnDefs(config, "task", taskColumns)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to get column definitions:", err)
-		os.Exit(1)
+		return table{}, fmt.Errorf("failed to get column definitions: %w", err)
 	}
 
-	data := [][]string{}
-
+	rows := []map[string]string{}
 	for _, node := range tasksResponse.Nodes {
 		for _, task := range node.Tasks {
-			rowData := map[string]string{
-				"NODE":         task.Node,
-				"ID":           fmt.Sprintf("%d", task.ID),
-				"ACTION":       task.Action,
-				"DESCRIPTION":  task.Description,
-				"START-TIME":   fmt.Sprintf("%d", task.StartTimeInMillis),
-				"RUNNING-TIME": fmt.Sprintf("%d", task.RunningTimeInNanos),
-			}
-
-			row := make([]string, len(columnDefs))
-			for i, colDef := range columnDefs {
-				row[i] = rowData[colDef.Header]
-			}
-			data = append(data, row)
+			rows = append(rows, taskRow(task))
 		}
 	}
 
-	if len(flagSortBy) > 0 {
-		output.PrintTable(columnDefs, data, flagSortBy...)
-	} else {
-		output.PrintTable(columnDefs, data, "NODE", "ID")
-	}
+	return buildTable(columnDefs, rows, "NODE", "ID"), nil
 }
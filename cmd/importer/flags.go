@@ -0,0 +1,12 @@
+package importer
+
+var (
+	flagBatchBytes  int
+	flagBatchSize   int
+	flagConcurrency int
+	flagErrorsFile  string
+	flagFile        string
+	flagIdField     string
+	flagOp          string
+	flagRate        float64
+)
@@ -0,0 +1,368 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/shared"
+	"github.com/spf13/cobra"
+)
+
+const (
+	opCreate = "create"
+	opIndex  = "index"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import INDEX",
+	Short: "Import NDJSON documents into an index",
+	Long: utils.Trim(`
+Import documents from an NDJSON file into an index using the bulk API.
+
+Every line is either a plain document, or an object with "_id" and "_source" as
+written by 'esctl export'. Documents are batched by count and size, and the
+failed items are written to an errors file at the end.
+
+Importing is refused when the current context is marked read-only.`),
+	Example: utils.TrimAndIndent(`
+# Import an export into a dev index.
+esctl import articles --file articles.ndjson
+
+# Create documents with the id taken from a field, at most 500 documents per second.
+esctl import articles --file articles.ndjson --id-field article.id --op create --rate 500
+
+# Read documents from stdin.
+cat articles.ndjson | esctl import articles --file -`),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handleImport(args[0])
+	},
+}
+
+func Cmd() *cobra.Command {
+	return importCmd
+}
+
+type document struct {
+	line   int
+	id     string
+	source json.RawMessage
+}
+
+type batch struct {
+	docs []document
+	body bytes.Buffer
+}
+
+type failure struct {
+	Line   int               `json:"line"`
+	ID     string            `json:"_id,omitempty"`
+	Status int               `json:"status"`
+	Error  *es.BulkItemError `json:"error"`
+	Source json.RawMessage   `json:"_source"`
+}
+
+type importer struct {
+	mu       sync.Mutex
+	index    string
+	imported int
+	failures []failure
+	throttle throttle
+}
+
+// throttle paces batches so that at most rate documents per second are sent.
+type throttle struct {
+	mu   sync.Mutex
+	rate float64
+	next time.Time
+}
+
+func (t *throttle) wait(docs int) {
+	if t.rate <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	at := t.next
+	t.next = t.next.Add(time.Duration(float64(docs) / t.rate * float64(time.Second)))
+	t.mu.Unlock()
+
+	time.Sleep(time.Until(at))
+}
+
+func handleImport(index string) {
+	if shared.ReadOnly {
+		fmt.Fprintln(os.Stderr, "Refusing to import: the current context is marked read-only")
+		os.Exit(1)
+	}
+
+	if flagOp != opCreate && flagOp != opIndex {
+		fmt.Fprintf(os.Stderr, "Unknown operation: %s (expected %s or %s)\n", flagOp, opCreate, opIndex)
+		os.Exit(1)
+	}
+
+	if flagConcurrency < 1 || flagBatchSize < 1 {
+		fmt.Fprintln(os.Stderr, "--concurrency and --batch-size must be at least 1")
+		os.Exit(1)
+	}
+
+	if flagFile == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		os.Exit(1)
+	}
+
+	var in io.Reader = os.Stdin
+	if flagFile != "-" {
+		file, err := os.Open(flagFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to open input file:", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	imp := &importer{
+		index:    index,
+		throttle: throttle{rate: flagRate},
+	}
+
+	batches := make(chan *batch, flagConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < flagConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				imp.send(b)
+			}
+		}()
+	}
+
+	err := readBatches(in, batches)
+	close(batches)
+	wg.Wait()
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read documents:", err)
+		os.Exit(1)
+	}
+
+	imp.report()
+}
+
+func readBatches(in io.Reader, batches chan<- *batch) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	current := &batch{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		doc, err := parseDocument(line, text)
+		if err != nil {
+			return err
+		}
+
+		action, err := json.Marshal(map[string]interface{}{flagOp: actionMetadata(doc)})
+		if err != nil {
+			return err
+		}
+
+		if len(current.docs) > 0 && current.body.Len()+len(action)+len(doc.source)+2 > flagBatchBytes {
+			batches <- current
+			current = &batch{}
+		}
+
+		current.docs = append(current.docs, doc)
+		current.body.Write(action)
+		current.body.WriteByte('\n')
+		current.body.Write(doc.source)
+		current.body.WriteByte('\n')
+
+		if len(current.docs) >= flagBatchSize {
+			batches <- current
+			current = &batch{}
+		}
+	}
+
+	if len(current.docs) > 0 {
+		batches <- current
+	}
+
+	return scanner.Err()
+}
+
+func actionMetadata(doc document) map[string]string {
+	metadata := map[string]string{}
+	if doc.id != "" {
+		metadata["_id"] = doc.id
+	}
+	return metadata
+}
+
+// parseDocument accepts both plain documents and the hits written by 'esctl export'.
+func parseDocument(line int, text []byte) (document, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(text, &object); err != nil {
+		return document{}, fmt.Errorf("line %d: %w", line, err)
+	}
+
+	doc := document{line: line, source: json.RawMessage(append([]byte(nil), text...))}
+	if source, ok := object["_source"]; ok {
+		doc.source = source
+		if id, ok := object["_id"]; ok {
+			if err := json.Unmarshal(id, &doc.id); err != nil {
+				return document{}, fmt.Errorf("line %d: invalid _id: %w", line, err)
+			}
+		}
+	}
+
+	if flagIdField != "" {
+		id, err := lookupField(doc.source, flagIdField)
+		if err != nil {
+			return document{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		doc.id = id
+	}
+
+	return doc, nil
+}
+
+// lookupField returns the value of a dot-separated path as a string, or an empty string if it is missing.
+// Numbers are returned as written in the source so that decimals and large integers are kept.
+func lookupField(source json.RawMessage, path string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(source))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", nil
+		}
+		value = object[key]
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("field %s is not a string or a number", path)
+	}
+}
+
+func (imp *importer) send(b *batch) {
+	imp.throttle.wait(len(b.docs))
+
+	response, err := es.Bulk(imp.index, b.body.Bytes())
+
+	imp.mu.Lock()
+	defer imp.mu.Unlock()
+
+	if err != nil {
+		for _, doc := range b.docs {
+			imp.failures = append(imp.failures, failure{
+				Line:   doc.line,
+				ID:     doc.id,
+				Error:  &es.BulkItemError{Type: "request_failed", Reason: err.Error()},
+				Source: doc.source,
+			})
+		}
+	} else {
+		for i, result := range response.Results() {
+			if result.Error == nil {
+				imp.imported++
+				continue
+			}
+			doc := b.docs[i]
+			imp.failures = append(imp.failures, failure{
+				Line:   doc.line,
+				ID:     result.ID,
+				Status: result.Status,
+				Error:  result.Error,
+				Source: doc.source,
+			})
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\rImported %d documents, %d failed", imp.imported, len(imp.failures))
+}
+
+func (imp *importer) report() {
+	fmt.Printf("Imported %d documents into %s.\n", imp.imported, imp.index)
+	if len(imp.failures) == 0 {
+		return
+	}
+
+	reasons := make(map[string]int)
+	for _, f := range imp.failures {
+		reasons[f.Error.Type]++
+	}
+	fmt.Printf("Failed to import %d documents:\n", len(imp.failures))
+	for reason, count := range reasons {
+		fmt.Printf("  %s: %d\n", reason, count)
+	}
+
+	file, err := os.Create(flagErrorsFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to create errors file:", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, f := range imp.failures {
+		line, err := json.Marshal(f)
+		if err != nil {
+			continue
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to write errors file:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Failed items were written to %s.\n", flagErrorsFile)
+	os.Exit(1)
+}
+
+func init() {
+	importCmd.Flags().StringVarP(&flagFile, "file", "f", "", "NDJSON file to import, or '-' to read from stdin")
+	importCmd.Flags().IntVar(&flagBatchSize, "batch-size", 1000, "Maximum number of documents per bulk request")
+	importCmd.Flags().IntVar(&flagBatchBytes, "batch-bytes", 5*1024*1024, "Maximum size of a bulk request in bytes")
+	importCmd.Flags().IntVar(&flagConcurrency, "concurrency", 2, "Number of bulk requests sent in parallel")
+	importCmd.Flags().Float64Var(&flagRate, "rate", 0, "Maximum number of documents imported per second (0 for unlimited)")
+	importCmd.Flags().StringVar(&flagIdField, "id-field", "", "Field of the document to use as document id")
+	importCmd.Flags().StringVar(&flagOp, "op", opIndex, "Bulk operation: create or index")
+	importCmd.Flags().StringVar(&flagErrorsFile, "errors-file", "import-errors.ndjson", "File to write the failed items to")
+}
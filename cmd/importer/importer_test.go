@@ -0,0 +1,180 @@
+package importer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupField(t *testing.T) {
+	tests := []struct {
+		source      string
+		path        string
+		expected    string
+		expectError bool
+	}{
+		{`{"id": "a1"}`, "id", "a1", false},
+		{`{"article": {"id": "a1"}}`, "article.id", "a1", false},
+		{`{"id": 42}`, "id", "42", false},
+		{`{"id": 1.5}`, "id", "1.5", false},
+		{`{"id": 9007199254740993}`, "id", "9007199254740993", false},
+		{`{"id": null}`, "id", "", false},
+		{`{"other": "a1"}`, "id", "", false},
+		{`{"article": "a1"}`, "article.id", "", false},
+		{`{"id": true}`, "id", "", true},
+		{`{"id": {"nested": 1}}`, "id", "", true},
+		{`{`, "id", "", true},
+	}
+
+	for _, test := range tests {
+		actual, err := lookupField(json.RawMessage(test.source), test.path)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Expected error for %s in %s, but got nil", test.path, test.source)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %s in %s: %v", test.path, test.source, err)
+		}
+		if actual != test.expected {
+			t.Errorf("%s in %s: expected %q, got %q", test.path, test.source, test.expected, actual)
+		}
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		idField        string
+		expectedID     string
+		expectedSource string
+		expectError    bool
+	}{
+		{"plain document", `{"title": "a"}`, "", "", `{"title": "a"}`, false},
+		{"exported hit", `{"_id": "1", "_index": "articles", "_source": {"title": "a"}}`, "", "1", `{"title": "a"}`, false},
+		{"exported hit without id", `{"_source": {"title": "a"}}`, "", "", `{"title": "a"}`, false},
+		{"id field", `{"article": {"id": 7}, "title": "a"}`, "article.id", "7", `{"article": {"id": 7}, "title": "a"}`, false},
+		{"id field overrides _id", `{"_id": "1", "_source": {"id": "x"}}`, "id", "x", `{"id": "x"}`, false},
+		{"invalid json", `{"title"`, "", "", "", true},
+		{"invalid _id", `{"_id": 1, "_source": {}}`, "", "", "", true},
+		{"invalid id field", `{"id": [1]}`, "id", "", "", true},
+	}
+
+	defer func(idField string) { flagIdField = idField }(flagIdField)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flagIdField = test.idField
+
+			doc, err := parseDocument(3, []byte(test.text))
+			if test.expectError {
+				if err == nil {
+					t.Fatal("Expected error, but got nil")
+				}
+				if !strings.HasPrefix(err.Error(), "line 3:") {
+					t.Errorf("Expected the error to name the line, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if doc.line != 3 || doc.id != test.expectedID || string(doc.source) != test.expectedSource {
+				t.Errorf("Expected line 3, id %q and source %s, got %+v", test.expectedID, test.expectedSource, doc)
+			}
+		})
+	}
+}
+
+func TestReadBatches(t *testing.T) {
+	input := strings.Join([]string{
+		`{"_id": "1", "_source": {"n": 1}}`,
+		``,
+		`{"_id": "2", "_source": {"n": 2}}`,
+		`{"n": 3}`,
+		`{"_id": "4", "_source": {"n": 4}}`,
+		`{"_id": "5", "_source": {"n": 5}}`,
+	}, "\n")
+
+	tests := []struct {
+		name       string
+		batchSize  int
+		batchBytes int
+		expected   [][]int
+	}{
+		{"by count", 2, 1024, [][]int{{1, 3}, {4, 5}, {6}}},
+		{"by size", 10, 80, [][]int{{1, 3}, {4, 5}, {6}}},
+		{"oversized document", 10, 1, [][]int{{1}, {3}, {4}, {5}, {6}}},
+	}
+
+	defer func(batchSize, batchBytes int, op, idField string) {
+		flagBatchSize, flagBatchBytes, flagOp, flagIdField = batchSize, batchBytes, op, idField
+	}(flagBatchSize, flagBatchBytes, flagOp, flagIdField)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flagBatchSize, flagBatchBytes, flagOp, flagIdField = test.batchSize, test.batchBytes, opCreate, ""
+
+			batches := make(chan *batch, 10)
+			if err := readBatches(strings.NewReader(input), batches); err != nil {
+				t.Fatal(err)
+			}
+			close(batches)
+
+			var actual [][]int
+			for b := range batches {
+				var lines []int
+				for _, doc := range b.docs {
+					lines = append(lines, doc.line)
+				}
+				actual = append(actual, lines)
+
+				if count := strings.Count(b.body.String(), "\n"); count != 2*len(b.docs) {
+					t.Errorf("Expected %d body lines, got %d", 2*len(b.docs), count)
+				}
+				if !strings.HasPrefix(b.body.String(), `{"create":`) {
+					t.Errorf("Expected create actions, got %s", b.body.String())
+				}
+			}
+
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected batches %v, got %v", test.expected, actual)
+			}
+		})
+	}
+
+	batches := make(chan *batch, 10)
+	if err := readBatches(strings.NewReader("{\"n\": 1}\nnot json\n"), batches); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("Expected an error on line 2, got %v", err)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	unlimited := &throttle{}
+	unlimited.wait(1000)
+	if !unlimited.next.IsZero() {
+		t.Errorf("Expected no pacing without a rate, got %v", unlimited.next)
+	}
+
+	limited := &throttle{rate: 1000}
+	start := time.Now()
+
+	// The first batch is sent immediately and books 20ms for its documents.
+	limited.wait(20)
+	if elapsed := time.Since(start); elapsed > 15*time.Millisecond {
+		t.Errorf("Expected the first batch not to wait, waited %s", elapsed)
+	}
+	if booked := limited.next.Sub(start); booked < 20*time.Millisecond || booked > 35*time.Millisecond {
+		t.Errorf("Expected 20ms to be booked, got %s", booked)
+	}
+
+	// The second batch waits until the documents of the first one are paced.
+	limited.wait(20)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected the second batch to wait for 20ms, waited %s", elapsed)
+	}
+}
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/fehmicansaglam/esctl/es"
+)
+
+// parseVariables converts key=value pairs into template variables.
+func parseVariables(pairs []string) (map[string]string, error) {
+	variables := make(map[string]string)
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid variable format: %s", pair)
+		}
+		variables[parts[0]] = parts[1]
+	}
+	return variables, nil
+}
+
+// renderBody executes the search body as a template and decodes the result.
+// Referencing a variable that was not set is an error.
+func renderBody(text string, variables map[string]string) (es.JsonResponse, error) {
+	tmpl, err := template.New("body").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse body template: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, variables); err != nil {
+		return nil, fmt.Errorf("failed to render body template: %w", err)
+	}
+
+	var body es.JsonResponse
+	if err := json.Unmarshal(rendered.Bytes(), &body); err != nil {
+		return nil, fmt.Errorf("failed to decode body: %w", err)
+	}
+
+	return body, nil
+}
+
+// readBody reads the search body from a file, or from stdin when path is "-".
+func readBody(path string, pairs []string) (es.JsonResponse, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	variables, err := parseVariables(pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderBody(string(content), variables)
+}
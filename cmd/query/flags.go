@@ -1,10 +1,15 @@
 package query
 
 var (
-	flagId     []string
-	flagTerm   []string
-	flagNested []string
-	flagSort   []string
-	flagFrom   int
-	flagSize   int
+	flagBody      string
+	flagFields    []string
+	flagHighlight []string
+	flagId        []string
+	flagTerm      []string
+	flagNested    []string
+	flagOutput    string
+	flagSet       []string
+	flagSort      []string
+	flagFrom      int
+	flagSize      int
 )
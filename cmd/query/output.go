@@ -0,0 +1,172 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+)
+
+func extractHits(response es.JsonResponse) []map[string]interface{} {
+	hitsObject, _ := response["hits"].(map[string]interface{})
+	rawHits, _ := hitsObject["hits"].([]interface{})
+
+	hits := make([]map[string]interface{}, 0, len(rawHits))
+	for _, rawHit := range rawHits {
+		if hit, ok := rawHit.(map[string]interface{}); ok {
+			hits = append(hits, hit)
+		}
+	}
+	return hits
+}
+
+func printHits(response es.JsonResponse) {
+	switch flagOutput {
+	case "json":
+		output.PrintJson(response["hits"])
+	case "ndjson":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		for _, hit := range extractHits(response) {
+			if err := encoder.Encode(hit); err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to generate JSON:", err)
+				os.Exit(1)
+			}
+		}
+	case "table":
+		columnDefs, data := buildHitsTable(extractHits(response), true)
+		output.PrintTable(columnDefs, data)
+	case "csv":
+		columnDefs, data := buildHitsTable(extractHits(response), false)
+		output.PrintCsv(columnDefs, data)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown output type: %s\n", flagOutput)
+		os.Exit(1)
+	}
+}
+
+// selectColumns returns the flattened source paths to show, following the order of
+// the requested fields. Without included fields every path is shown.
+func selectColumns(keys []string, fields []string) []string {
+	var includes, excludes []string
+	for _, field := range fields {
+		if strings.HasPrefix(field, "-") {
+			excludes = append(excludes, strings.TrimPrefix(field, "-"))
+		} else {
+			includes = append(includes, field)
+		}
+	}
+
+	matches := func(key, field string) bool {
+		if key == field || strings.HasPrefix(key, field+".") {
+			return true
+		}
+		matched, _ := path.Match(field, key)
+		return matched
+	}
+
+	excluded := func(key string) bool {
+		for _, field := range excludes {
+			if matches(key, field) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var columns []string
+	if len(includes) == 0 {
+		for _, key := range keys {
+			if !excluded(key) {
+				columns = append(columns, key)
+			}
+		}
+		return columns
+	}
+
+	seen := make(map[string]bool)
+	for _, field := range includes {
+		var fieldColumns []string
+		for _, key := range keys {
+			if !seen[key] && !excluded(key) && matches(key, field) {
+				fieldColumns = append(fieldColumns, key)
+				seen[key] = true
+			}
+		}
+		sort.Strings(fieldColumns)
+		columns = append(columns, fieldColumns...)
+	}
+	return columns
+}
+
+func formatHighlight(hit map[string]interface{}) string {
+	highlight, _ := hit["highlight"].(map[string]interface{})
+
+	fields := make([]string, 0, len(highlight))
+	for field := range highlight {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var parts []string
+	for _, field := range fields {
+		fragments, _ := highlight[field].([]interface{})
+		texts := make([]string, 0, len(fragments))
+		for _, fragment := range fragments {
+			texts = append(texts, fmt.Sprint(fragment))
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", field, strings.Join(texts, " ... ")))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func buildHitsTable(hits []map[string]interface{}, singleLine bool) ([]output.ColumnDef, [][]string) {
+	sources := make([]map[string]string, len(hits))
+	for i, hit := range hits {
+		source, _ := hit["_source"].(map[string]interface{})
+		sources[i] = output.Flatten(source)
+	}
+
+	columns := selectColumns(output.FlattenedKeys(sources), flagFields)
+
+	columnDefs := []output.ColumnDef{
+		{Header: "_ID", Type: output.Text},
+		{Header: "_SCORE", Type: output.Number},
+	}
+	for _, column := range columns {
+		columnDefs = append(columnDefs, output.ColumnDef{Header: strings.ToUpper(column), Type: output.Text})
+	}
+	if len(flagHighlight) > 0 {
+		columnDefs = append(columnDefs, output.ColumnDef{Header: "HIGHLIGHT", Type: output.Text})
+	}
+
+	clean := func(value string) string {
+		if singleLine {
+			return strings.Join(strings.Fields(value), " ")
+		}
+		return value
+	}
+
+	data := [][]string{}
+	for i, hit := range hits {
+		row := []string{fmt.Sprint(hit["_id"]), ""}
+		if score, ok := hit["_score"].(float64); ok {
+			row[1] = strconv.FormatFloat(score, 'f', -1, 64)
+		}
+		for _, column := range columns {
+			row = append(row, clean(sources[i][column]))
+		}
+		if len(flagHighlight) > 0 {
+			row = append(row, clean(formatHighlight(hit)))
+		}
+		data = append(data, row)
+	}
+
+	return columnDefs, data
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/fehmicansaglam/esctl/cmd/utils"
 	"github.com/fehmicansaglam/esctl/es"
-	"github.com/fehmicansaglam/esctl/output"
 	"github.com/spf13/cobra"
 )
 
@@ -18,17 +17,55 @@ var queryCmd = &cobra.Command{
 esctl query articles
 esctl query articles --id 61
 esctl query articles --term "price:10" --size 1
-esctl query articles --sort "price:desc" --from 10 --size 10`),
+esctl query articles --sort "price:desc" --from 10 --size 10
+esctl query articles --size 20 --fields title,author.name -o table
+esctl query articles --body search.json --highlight title -o table
+esctl query articles --fields -body --size 100 -o csv > articles.csv
+esctl query articles --body queries/by-author.json --set author=jane
+cat query.json | esctl query articles --body - --term "status:published"`),
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		index := args[0]
 
-		response, err := es.SearchDocuments(index, flagId, flagTerm, flagFrom, flagSize, flagNested, flagSort)
+		var body es.JsonResponse
+		from, size := flagFrom, flagSize
+		if flagBody != "" {
+			var err error
+			body, err = readBody(flagBody, flagSet)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to load query body:", err)
+				os.Exit(1)
+			}
+			// Keep from and size of the body unless they are explicitly overridden.
+			if !cmd.Flags().Changed("from") {
+				from = -1
+			}
+			if !cmd.Flags().Changed("size") {
+				size = -1
+			}
+		} else if len(flagSet) > 0 {
+			fmt.Fprintln(os.Stderr, "--set can only be used together with --body")
+			os.Exit(1)
+		}
+
+		if len(flagFields) > 0 || len(flagHighlight) > 0 {
+			if body == nil {
+				body = es.JsonResponse{}
+			}
+			if len(flagFields) > 0 {
+				es.SetSourceFilter(body, flagFields)
+			}
+			if len(flagHighlight) > 0 {
+				es.SetHighlight(body, flagHighlight)
+			}
+		}
+
+		response, err := es.SearchDocuments(index, body, flagId, flagTerm, from, size, flagNested, flagSort)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Failed to query:", err)
 			os.Exit(1)
 		}
-		output.PrintJson(response["hits"])
+		printHits(response)
 	},
 }
 
@@ -37,10 +74,15 @@ func Cmd() *cobra.Command {
 }
 
 func init() {
+	queryCmd.Flags().StringVar(&flagBody, "body", "", "File containing the search body, or '-' to read it from stdin")
+	queryCmd.Flags().StringArrayVar(&flagSet, "set", []string{}, "Template variable(s) for the body in key=value format")
 	queryCmd.Flags().StringArrayVar(&flagId, "id", []string{}, "Document IDs to fetch")
 	queryCmd.Flags().StringArrayVarP(&flagTerm, "term", "t", []string{}, "Term filter(s)")
 	queryCmd.Flags().StringArrayVar(&flagNested, "nested", []string{}, "Nested path(s)")
 	queryCmd.Flags().StringArrayVarP(&flagSort, "sort", "s", []string{}, "Sort definition(s)")
 	queryCmd.Flags().IntVar(&flagFrom, "from", 0, "Starting document offset")
 	queryCmd.Flags().IntVar(&flagSize, "size", 1, "Number of hits to return")
+	queryCmd.Flags().StringSliceVar(&flagFields, "fields", []string{}, "Source fields to return (comma-separated), prefix a field with '-' to exclude it")
+	queryCmd.Flags().StringArrayVar(&flagHighlight, "highlight", []string{}, "Field(s) to highlight")
+	queryCmd.Flags().StringVarP(&flagOutput, "output", "o", "json", "Print output as json, ndjson, table or csv")
 }
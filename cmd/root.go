@@ -5,11 +5,20 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/fehmicansaglam/esctl/cmd/analyze"
 	"github.com/fehmicansaglam/esctl/cmd/config"
 	"github.com/fehmicansaglam/esctl/cmd/count"
 	"github.com/fehmicansaglam/esctl/cmd/describe"
+	"github.com/fehmicansaglam/esctl/cmd/diagnostics"
+	"github.com/fehmicansaglam/esctl/cmd/diff"
+	"github.com/fehmicansaglam/esctl/cmd/doctor"
+	"github.com/fehmicansaglam/esctl/cmd/export"
+	"github.com/fehmicansaglam/esctl/cmd/forecast"
 	"github.com/fehmicansaglam/esctl/cmd/get"
+	"github.com/fehmicansaglam/esctl/cmd/importer"
 	"github.com/fehmicansaglam/esctl/cmd/query"
+	"github.com/fehmicansaglam/esctl/cmd/top"
+	"github.com/fehmicansaglam/esctl/cmd/wait"
 	"github.com/fehmicansaglam/esctl/constants"
 	"github.com/fehmicansaglam/esctl/shared"
 	"github.com/spf13/cobra"
@@ -18,7 +27,7 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "esctl",
 	Short: "esctl is CLI for Elasticsearch",
-	Long:  `esctl is a read-only CLI for Elasticsearch that allows users to manage and monitor their Elasticsearch clusters.`,
+	Long:  `esctl is a CLI for Elasticsearch that allows users to manage and monitor their Elasticsearch clusters.`,
 }
 
 func Execute() {
@@ -39,15 +48,33 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&shared.Context, "context", "", "Override context")
 	rootCmd.PersistentFlags().BoolVar(&shared.Debug, "debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().StringVar(&shared.BundlePath, "from-bundle", "", "Serve requests from a diagnostics bundle or a directory of saved responses instead of the cluster")
 
+	rootCmd.AddCommand(analyze.Cmd())
 	rootCmd.AddCommand(config.Cmd())
 	rootCmd.AddCommand(count.Cmd())
 	rootCmd.AddCommand(describe.Cmd())
+	rootCmd.AddCommand(diagnostics.Cmd())
+	rootCmd.AddCommand(diff.Cmd())
+	rootCmd.AddCommand(doctor.Cmd())
+	rootCmd.AddCommand(export.Cmd())
+	rootCmd.AddCommand(forecast.Cmd())
 	rootCmd.AddCommand(get.Cmd())
+	rootCmd.AddCommand(importer.Cmd())
 	rootCmd.AddCommand(query.Cmd())
+	rootCmd.AddCommand(top.Cmd())
+	rootCmd.AddCommand(wait.Cmd())
 }
 
 func initialize() {
+	if shared.BundlePath != "" {
+		if err := diagnostics.UseBundle(shared.BundlePath); err != nil {
+			fmt.Printf("Error: Failed to open bundle '%s': %v\n", shared.BundlePath, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if shared.ElasticsearchHost == "" {
 		conf := config.ParseConfigFile()
 		readContextFromConfig(conf)
@@ -70,31 +97,14 @@ func readContextFromConfig(conf config.Config) {
 		context = conf.Contexts[0].Name
 	}
 
-	clusterFound := false
-	for _, cluster := range conf.Contexts {
-		if cluster.Name == context {
-			shared.ElasticsearchProtocol = cluster.Protocol
-			if shared.ElasticsearchProtocol == "" {
-				shared.ElasticsearchProtocol = constants.DefaultElasticsearchProtocol
-			}
-			shared.ElasticsearchPort = cluster.Port
-			if shared.ElasticsearchPort == 0 {
-				shared.ElasticsearchPort = constants.DefaultElasticsearchPort
-			}
-			shared.ElasticsearchUsername = cluster.Username
-			shared.ElasticsearchPassword = cluster.Password
-			shared.ElasticsearchHost = cluster.Host
-			if shared.ElasticsearchHost == "" {
-				fmt.Println("Error: 'host' field is not specified in the configuration for the current cluster.")
-				os.Exit(1)
-			}
-			clusterFound = true
-			break
-		}
+	cluster, ok := conf.FindContext(context)
+	if !ok {
+		fmt.Printf("Error: No cluster found with the name '%s' in the configuration.\n", context)
+		os.Exit(1)
 	}
 
-	if !clusterFound {
-		fmt.Printf("Error: No cluster found with the name '%s' in the configuration.\n", conf.CurrentContext)
+	if err := cluster.Apply(); err != nil {
+		fmt.Printf("Error: %v.\n", err)
 		os.Exit(1)
 	}
 }
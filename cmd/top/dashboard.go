@@ -0,0 +1,255 @@
+package top
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+)
+
+type pane int
+
+const (
+	paneNodes pane = iota
+	paneIndices
+)
+
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionRefresh
+)
+
+var (
+	nodeSorts  = []string{"name", "heap", "cpu", "disk"}
+	indexSorts = []string{"indexing", "search", "docs", "store", "name"}
+)
+
+type dashboard struct {
+	current *snapshot
+	rates   map[string]es.OperationRates
+	err     error
+
+	focus    pane
+	selected [2]int
+	sorts    [2]int
+
+	filter        string
+	editingFilter bool
+
+	drilled   bool
+	drillPane pane
+	drillName string
+	scroll    int
+}
+
+func (d *dashboard) update(s sample) {
+	if s.err != nil {
+		d.err = s.err
+		return
+	}
+
+	d.err = nil
+	if d.current != nil {
+		d.rates = indexRates(*d.current, s.snapshot)
+	}
+	d.current = &s.snapshot
+}
+
+func (d *dashboard) handleKey(key string) action {
+	if d.editingFilter {
+		switch key {
+		case keyEnter:
+			d.editingFilter = false
+		case keyEscape:
+			d.editingFilter = false
+			d.filter = ""
+		case keyBackspace:
+			if len(d.filter) > 0 {
+				d.filter = d.filter[:len(d.filter)-1]
+			}
+		case keyCtrlC:
+			return actionQuit
+		default:
+			if len(key) == 1 {
+				d.filter += key
+			}
+		}
+		d.selected = [2]int{}
+		return actionNone
+	}
+
+	switch key {
+	case "q", keyCtrlC:
+		return actionQuit
+	case "r":
+		return actionRefresh
+	case "/":
+		d.editingFilter = true
+	case "s":
+		sorts := nodeSorts
+		if d.focus == paneIndices {
+			sorts = indexSorts
+		}
+		d.sorts[d.focus] = (d.sorts[d.focus] + 1) % len(sorts)
+	case keyTab:
+		if !d.drilled {
+			d.focus = 1 - d.focus
+		}
+	case keyUp, "k":
+		d.move(-1)
+	case keyDown, "j":
+		d.move(1)
+	case keyEnter, keyRight, "l":
+		d.drill()
+	case keyEscape, keyBackspace, keyLeft, "h":
+		d.drilled = false
+	}
+
+	return actionNone
+}
+
+func (d *dashboard) move(offset int) {
+	if d.drilled {
+		d.scroll = clamp(d.scroll+offset, 0, 1<<30)
+		return
+	}
+
+	count := len(d.nodes())
+	if d.focus == paneIndices {
+		count = len(d.indices())
+	}
+	d.selected[d.focus] = clamp(d.selected[d.focus]+offset, 0, count-1)
+}
+
+func (d *dashboard) drill() {
+	if d.drilled || d.current == nil {
+		return
+	}
+
+	switch d.focus {
+	case paneNodes:
+		nodes := d.nodes()
+		if len(nodes) == 0 {
+			return
+		}
+		d.drillName = nodes[clamp(d.selected[paneNodes], 0, len(nodes)-1)].Name
+	case paneIndices:
+		indices := d.indices()
+		if len(indices) == 0 {
+			return
+		}
+		d.drillName = indices[clamp(d.selected[paneIndices], 0, len(indices)-1)].Index
+	}
+
+	d.drilled = true
+	d.drillPane = d.focus
+	d.scroll = 0
+}
+
+func (d *dashboard) matchesFilter(name string) bool {
+	return d.filter == "" || strings.Contains(name, d.filter)
+}
+
+// nodes returns the nodes matching the filter in the selected sort order.
+func (d *dashboard) nodes() []es.Node {
+	if d.current == nil {
+		return nil
+	}
+
+	var nodes []es.Node
+	for _, node := range d.current.nodes {
+		if d.matchesFilter(node.Name) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	var key func(es.Node) float64
+	switch nodeSorts[d.sorts[paneNodes]] {
+	case "heap":
+		key = func(node es.Node) float64 { return parseNumber(node.HeapPercent) }
+	case "cpu":
+		key = func(node es.Node) float64 { return parseNumber(node.CPU) }
+	case "disk":
+		key = diskPercent
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if key != nil {
+			if left, right := key(nodes[i]), key(nodes[j]); left != right {
+				return left > right
+			}
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return nodes
+}
+
+// indices returns the indices matching the filter in the selected sort order.
+func (d *dashboard) indices() []es.Index {
+	if d.current == nil {
+		return nil
+	}
+
+	var indices []es.Index
+	for _, index := range d.current.indices {
+		if d.matchesFilter(index.Index) {
+			indices = append(indices, index)
+		}
+	}
+
+	var key func(es.Index) float64
+	switch indexSorts[d.sorts[paneIndices]] {
+	case "indexing":
+		key = func(index es.Index) float64 { return d.rates[index.Index].IndexingRate }
+	case "search":
+		key = func(index es.Index) float64 { return d.rates[index.Index].SearchRate }
+	case "docs":
+		key = func(index es.Index) float64 { return parseNumber(index.DocsCount) }
+	case "store":
+		key = func(index es.Index) float64 {
+			size, _ := output.ParseDataSize(index.StoreSize)
+			return size
+		}
+	}
+
+	sort.SliceStable(indices, func(i, j int) bool {
+		if key != nil {
+			if left, right := key(indices[i]), key(indices[j]); left != right {
+				return left > right
+			}
+		}
+		return indices[i].Index < indices[j].Index
+	})
+
+	return indices
+}
+
+func parseNumber(value string) float64 {
+	number, _ := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	return number
+}
+
+func diskPercent(node es.Node) float64 {
+	used, _ := output.ParseDataSize(node.DiskUsed)
+	total, _ := output.ParseDataSize(node.DiskTotal)
+	if total == 0 {
+		return 0
+	}
+	return used / total * 100
+}
+
+func clamp(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
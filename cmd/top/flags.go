@@ -0,0 +1,7 @@
+package top
+
+import "time"
+
+var (
+	flagInterval time.Duration
+)
@@ -0,0 +1,476 @@
+package top
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+	"golang.org/x/term"
+)
+
+const (
+	cursorHome  = "\033[H"
+	clearLine   = "\033[K"
+	clearBelow  = "\033[J"
+	bold        = "\033[1m"
+	reverse     = "\033[7m"
+	reset       = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+
+	barWidth    = 10
+	maxSideRows = 5
+)
+
+var nodeColumns = []output.ColumnDef{
+	{Header: "NAME", Type: output.Text},
+	{Header: "ROLE", Type: output.Text},
+	{Header: "MASTER", Type: output.Text},
+	{Header: "HEAP", Type: output.Text},
+	{Header: "CPU", Type: output.Text},
+	{Header: "DISK", Type: output.Text},
+	{Header: "LOAD-1M", Type: output.Number},
+	{Header: "UPTIME", Type: output.Text},
+}
+
+var indexColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "HEALTH", Type: output.Text},
+	{Header: "STATUS", Type: output.Text},
+	{Header: "PRI", Type: output.Number},
+	{Header: "REP", Type: output.Number},
+	{Header: "DOCS", Type: output.Number},
+	{Header: "STORE", Type: output.DataSize},
+	{Header: "INDEXING/S", Type: output.Number},
+	{Header: "SEARCH/S", Type: output.Number},
+	{Header: "LATENCY-MS", Type: output.Number},
+}
+
+var threadPoolColumns = []output.ColumnDef{
+	{Header: "NODE", Type: output.Text},
+	{Header: "POOL", Type: output.Text},
+	{Header: "ACTIVE", Type: output.Number},
+	{Header: "QUEUE", Type: output.Number},
+	{Header: "REJECTED", Type: output.Number},
+}
+
+var recoveryColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "SHARD", Type: output.Number},
+	{Header: "TYPE", Type: output.Text},
+	{Header: "STAGE", Type: output.Text},
+	{Header: "SOURCE", Type: output.Text},
+	{Header: "TARGET", Type: output.Text},
+	{Header: "BYTES", Type: output.Percent},
+	{Header: "TIME", Type: output.Text},
+}
+
+var shardColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "SHARD", Type: output.Number},
+	{Header: "PRI-REP", Type: output.Text},
+	{Header: "STATE", Type: output.Text},
+	{Header: "NODE", Type: output.Text},
+	{Header: "DOCS", Type: output.Number},
+	{Header: "STORE", Type: output.DataSize},
+	{Header: "UNASSIGNED-REASON", Type: output.Text},
+}
+
+// draw writes one frame of the dashboard over the previous one.
+func (d *dashboard) draw(w io.Writer) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 120, 40
+	}
+
+	lines := d.banner()
+	bodyHeight := height - len(lines) - 1
+
+	switch {
+	case d.current == nil:
+		lines = append(lines, "", "Loading...")
+	case d.drilled && d.drillPane == paneNodes:
+		lines = append(lines, d.nodeDetails(bodyHeight)...)
+	case d.drilled && d.drillPane == paneIndices:
+		lines = append(lines, d.indexDetails(bodyHeight)...)
+	default:
+		lines = append(lines, d.overview(bodyHeight)...)
+	}
+
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines[:height-1], d.footer())
+
+	var screen strings.Builder
+	screen.WriteString(cursorHome)
+	for i, line := range lines {
+		if i > 0 {
+			screen.WriteString("\r\n")
+		}
+		screen.WriteString(fit(line, width) + clearLine)
+	}
+	screen.WriteString(clearBelow)
+	fmt.Fprint(w, screen.String())
+}
+
+func (d *dashboard) banner() []string {
+	now := time.Now().Format("15:04:05")
+	if d.current == nil {
+		return []string{bold + "esctl top" + reset + "  " + now, ""}
+	}
+
+	health := d.current.health
+	return []string{
+		fmt.Sprintf("%sesctl top%s - %s  status: %s  nodes: %d  data nodes: %d  %s",
+			bold, reset, health.ClusterName, colorStatus(health.Status), health.NumberOfNodes, health.NumberOfDataNodes, now),
+		fmt.Sprintf("shards: %d active, %d primary, %d relocating, %d initializing, %d unassigned  pending tasks: %d",
+			health.ActiveShards, health.ActivePrimaryShards, health.RelocatingShards, health.InitializingShards,
+			health.UnassignedShards, health.NumberOfPendingTasks),
+	}
+}
+
+func (d *dashboard) footer() string {
+	if d.editingFilter {
+		return "/" + d.filter + "_"
+	}
+	if d.err != nil {
+		return colorRed + d.err.Error() + reset
+	}
+
+	footer := "tab switch  up/down select  enter details  esc back  s sort  / filter  r refresh  q quit"
+	if d.filter != "" {
+		footer += "  filter: " + d.filter
+	}
+	return footer
+}
+
+func (d *dashboard) overview(height int) []string {
+	var threadPools, recoveries []string
+	if data := d.threadPoolData(""); len(data) > 0 {
+		threadPools = append(section("THREAD POOLS", threadPoolColumns, data, -1, maxSideRows), "")
+	}
+	if data := d.recoveryData(""); len(data) > 0 {
+		recoveries = append(section("RECOVERIES", recoveryColumns, data, -1, maxSideRows), "")
+	}
+	remaining := height - len(threadPools) - len(recoveries) - 7
+
+	nodes := d.nodes()
+	nodeRows := clamp(len(nodes), 1, max(remaining/3, 3))
+	indexRows := max(remaining-nodeRows, 1)
+
+	lines := []string{""}
+	lines = append(lines, section(d.paneTitle(paneNodes, "NODES", nodeSorts), nodeColumns, d.nodeData(nodes), d.selection(paneNodes), nodeRows)...)
+	lines = append(lines, "")
+	lines = append(lines, section(d.paneTitle(paneIndices, "INDICES", indexSorts), indexColumns, d.indexData(d.indices()), d.selection(paneIndices), indexRows)...)
+	lines = append(lines, "")
+	lines = append(lines, threadPools...)
+	lines = append(lines, recoveries...)
+	return lines
+}
+
+func (d *dashboard) paneTitle(p pane, title string, sorts []string) string {
+	title = fmt.Sprintf("%s (sort: %s)", title, sorts[d.sorts[p]])
+	if d.focus == p {
+		return bold + title + reset
+	}
+	return title
+}
+
+// selection returns the selected row of the pane, or -1 when the pane is not focused.
+func (d *dashboard) selection(p pane) int {
+	if d.focus != p {
+		return -1
+	}
+	return d.selected[p]
+}
+
+func (d *dashboard) nodeDetails(height int) []string {
+	var node *es.Node
+	for i := range d.current.nodes {
+		if d.current.nodes[i].Name == d.drillName {
+			node = &d.current.nodes[i]
+		}
+	}
+	if node == nil {
+		return []string{"", fmt.Sprintf("Node %s is no longer part of the cluster.", d.drillName)}
+	}
+
+	lines := []string{
+		"",
+		bold + "NODE " + node.Name + reset,
+		fmt.Sprintf("ip: %s  roles: %s  master: %s  load: %s  uptime: %s", node.IP, node.NodeRole, node.Master, node.Load1m, node.Uptime),
+		colorBars(fmt.Sprintf("heap %s  %s / %s", usageBar(parseNumber(node.HeapPercent)), node.HeapCurrent, node.HeapMax)),
+		colorBars(fmt.Sprintf("ram  %s  %s / %s", usageBar(parseNumber(node.RAMPercent)), node.RAMCurrent, node.RAMMax)),
+		colorBars(fmt.Sprintf("cpu  %s", usageBar(parseNumber(node.CPU)))),
+		colorBars(fmt.Sprintf("disk %s  %s / %s", usageBar(diskPercent(*node)), node.DiskUsed, node.DiskTotal)),
+		"",
+	}
+
+	if data := d.threadPoolData(node.Name); len(data) > 0 {
+		lines = append(lines, section("THREAD POOLS", threadPoolColumns, data, -1, maxSideRows)...)
+		lines = append(lines, "")
+	}
+
+	var shards []es.Shard
+	for _, shard := range d.current.shards {
+		if shard.Node == node.Name {
+			shards = append(shards, shard)
+		}
+	}
+
+	return append(lines, d.shardSection(shards, height-len(lines)-2)...)
+}
+
+func (d *dashboard) indexDetails(height int) []string {
+	var index *es.Index
+	for i := range d.current.indices {
+		if d.current.indices[i].Index == d.drillName {
+			index = &d.current.indices[i]
+		}
+	}
+	if index == nil {
+		return []string{"", fmt.Sprintf("Index %s no longer exists.", d.drillName)}
+	}
+
+	lines := []string{
+		"",
+		bold + "INDEX " + index.Index + reset,
+		fmt.Sprintf("health: %s  status: %s  primaries: %s  replicas: %s  created: %s",
+			colorStatus(index.Health), index.Status, index.Pri, index.Rep, index.CreationDate),
+		fmt.Sprintf("docs: %s  deleted: %s  store: %s  primary store: %s",
+			index.DocsCount, index.DocsDeleted, index.StoreSize, index.PriStoreSize),
+	}
+
+	if rates, ok := d.rates[index.Index]; ok {
+		lines = append(lines, fmt.Sprintf("indexing: %s/s  search: %s/s  query latency: %s ms  merges: %s ms/s  refresh: %s ms/s",
+			formatRate(rates.IndexingRate), formatRate(rates.SearchRate), formatRate(rates.QueryLatency),
+			formatRate(rates.MergeTimeRate), formatRate(rates.RefreshRate)))
+	}
+	lines = append(lines, "")
+
+	if data := d.recoveryData(index.Index); len(data) > 0 {
+		lines = append(lines, section("RECOVERIES", recoveryColumns, data, -1, maxSideRows)...)
+		lines = append(lines, "")
+	}
+
+	var shards []es.Shard
+	for _, shard := range d.current.shards {
+		if shard.Index == index.Index {
+			shards = append(shards, shard)
+		}
+	}
+
+	return append(lines, d.shardSection(shards, height-len(lines)-2)...)
+}
+
+func (d *dashboard) shardSection(shards []es.Shard, rows int) []string {
+	data := make([][]string, 0, len(shards))
+	for _, shard := range shards {
+		data = append(data, []string{
+			shard.Index, shard.Shard, shard.PriRep, shard.State, shard.Node,
+			shard.Docs, shard.Store, shard.UnassignedReason,
+		})
+	}
+	sort.SliceStable(data, func(i, j int) bool {
+		if data[i][0] != data[j][0] {
+			return data[i][0] < data[j][0]
+		}
+		if left, right := parseNumber(data[i][1]), parseNumber(data[j][1]); left != right {
+			return left < right
+		}
+		return data[i][2] < data[j][2]
+	})
+
+	d.scroll = clamp(d.scroll, 0, len(data)-1)
+	return section("SHARDS", shardColumns, data, d.scroll, max(rows, 1))
+}
+
+func (d *dashboard) nodeData(nodes []es.Node) [][]string {
+	data := make([][]string, 0, len(nodes))
+	for _, node := range nodes {
+		data = append(data, []string{
+			node.Name,
+			node.NodeRole,
+			node.Master,
+			usageBar(parseNumber(node.HeapPercent)),
+			usageBar(parseNumber(node.CPU)),
+			usageBar(diskPercent(node)),
+			node.Load1m,
+			node.Uptime,
+		})
+	}
+	return data
+}
+
+func (d *dashboard) indexData(indices []es.Index) [][]string {
+	data := make([][]string, 0, len(indices))
+	for _, index := range indices {
+		row := []string{
+			index.Index, index.Health, index.Status, index.Pri, index.Rep,
+			index.DocsCount, index.StoreSize, "", "", "",
+		}
+		if rates, ok := d.rates[index.Index]; ok {
+			row[7] = formatRate(rates.IndexingRate)
+			row[8] = formatRate(rates.SearchRate)
+			row[9] = formatRate(rates.QueryLatency)
+		}
+		data = append(data, row)
+	}
+	return data
+}
+
+// threadPoolData returns the thread pools with queued or rejected tasks, the busiest
+// first. When node is given, every busy thread pool of the node is returned.
+func (d *dashboard) threadPoolData(node string) [][]string {
+	var data [][]string
+	for _, pool := range d.current.threadPools {
+		busy := parseNumber(pool.Queue) > 0 || parseNumber(pool.Rejected) > 0
+		if node != "" {
+			if pool.NodeName != node || !(busy || parseNumber(pool.Active) > 0) {
+				continue
+			}
+		} else if !busy || !d.matchesFilter(pool.NodeName) {
+			continue
+		}
+		data = append(data, []string{pool.NodeName, pool.Name, pool.Active, pool.Queue, pool.Rejected})
+	}
+
+	sort.SliceStable(data, func(i, j int) bool {
+		return parseNumber(data[i][4]) > parseNumber(data[j][4])
+	})
+	return data
+}
+
+// recoveryData returns the active recoveries. When index is given, only the recoveries
+// of the index are returned.
+func (d *dashboard) recoveryData(index string) [][]string {
+	var data [][]string
+	for _, recovery := range d.current.recoveries {
+		if index != "" && recovery.Index != index {
+			continue
+		}
+		if index == "" && !d.matchesFilter(recovery.Index) {
+			continue
+		}
+		data = append(data, []string{
+			recovery.Index, recovery.Shard, recovery.Type, recovery.Stage,
+			recovery.SourceNode, recovery.TargetNode, recovery.BytesPercent, recovery.Time,
+		})
+	}
+	return data
+}
+
+// section renders a titled table showing at most rows rows. The window scrolls to keep
+// the selected row visible and highlights it; a negative selected highlights nothing.
+func section(title string, columnDefs []output.ColumnDef, data [][]string, selected, rows int) []string {
+	if len(data) == 0 {
+		return []string{title, "  (none)"}
+	}
+
+	var buf bytes.Buffer
+	output.RenderTable(&buf, columnDefs, data)
+	tableLines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	header, body := tableLines[0], tableLines[1:]
+
+	offset := 0
+	if selected >= rows {
+		offset = selected - rows + 1
+	}
+	end := offset + rows
+	if end > len(body) {
+		end = len(body)
+	}
+	if len(body) > rows {
+		title += fmt.Sprintf(" [%d-%d of %d]", offset+1, end, len(body))
+	}
+
+	lines := []string{title, header}
+	for i := offset; i < end; i++ {
+		line := colorBars(body[i])
+		if i == selected {
+			line = reverse + strings.ReplaceAll(line, reset, reset+reverse) + reset
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+var barRegexp = regexp.MustCompile(`\[(\|*)( *)\] +(\d+)%`)
+
+// usageBar draws a percentage as a bar. Bars are coloured by colorBars once the lines are
+// laid out, so that the escape sequences do not count towards the column widths.
+func usageBar(percent float64) string {
+	filled := clamp(int(percent/100*barWidth+0.5), 0, barWidth)
+	return fmt.Sprintf("[%s%s] %3.0f%%", strings.Repeat("|", filled), strings.Repeat(" ", barWidth-filled), percent)
+}
+
+// colorBars colours the bars of the line by how close they are to full.
+func colorBars(line string) string {
+	return barRegexp.ReplaceAllStringFunc(line, func(bar string) string {
+		match := barRegexp.FindStringSubmatch(bar)
+		percent := parseNumber(match[3])
+
+		color := colorGreen
+		if percent >= 85 {
+			color = colorRed
+		} else if percent >= 70 {
+			color = colorYellow
+		}
+
+		return "[" + color + match[1] + reset + bar[1+len(match[1]):]
+	})
+}
+
+func colorStatus(status string) string {
+	switch status {
+	case "green":
+		return colorGreen + status + reset
+	case "yellow":
+		return colorYellow + status + reset
+	case "red":
+		return colorRed + status + reset
+	}
+	return status
+}
+
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.1f", rate)
+}
+
+// fit cuts the line to the given number of visible characters, keeping the escape
+// sequences so that colours are still reset.
+func fit(line string, width int) string {
+	var b strings.Builder
+	visible := 0
+	escape := false
+	for _, r := range line {
+		switch {
+		case escape:
+			b.WriteRune(r)
+			escape = !(r >= '@' && r <= '~' && r != '[')
+		case r == '\033':
+			b.WriteRune(r)
+			escape = true
+		case visible < width:
+			b.WriteRune(r)
+			visible++
+		}
+	}
+	return b.String()
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
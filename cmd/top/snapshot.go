@@ -0,0 +1,68 @@
+package top
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/es"
+)
+
+// snapshot is the state of the cluster at one refresh of the dashboard.
+type snapshot struct {
+	takenAt     time.Time
+	health      es.ClusterHealth
+	nodes       []es.Node
+	indices     []es.Index
+	shards      []es.Shard
+	indexStats  map[string]es.OperationStats
+	threadPools []es.ThreadPool
+	recoveries  []es.Recovery
+}
+
+type sample struct {
+	snapshot snapshot
+	err      error
+}
+
+func takeSnapshot() (snapshot, error) {
+	var s snapshot
+	var err error
+
+	s.takenAt = time.Now()
+
+	if s.health, err = es.GetClusterHealth(); err != nil {
+		return snapshot{}, fmt.Errorf("failed to retrieve cluster health: %w", err)
+	}
+	if s.nodes, err = es.GetNodes(""); err != nil {
+		return snapshot{}, fmt.Errorf("failed to retrieve nodes: %w", err)
+	}
+	if s.indices, err = es.GetIndices(""); err != nil {
+		return snapshot{}, fmt.Errorf("failed to retrieve indices: %w", err)
+	}
+	if s.shards, err = es.GetShards(""); err != nil {
+		return snapshot{}, fmt.Errorf("failed to retrieve shards: %w", err)
+	}
+	if s.indexStats, err = es.GetIndexStats(""); err != nil {
+		return snapshot{}, fmt.Errorf("failed to retrieve index stats: %w", err)
+	}
+	if s.threadPools, err = es.GetThreadPools(); err != nil {
+		return snapshot{}, fmt.Errorf("failed to retrieve thread pools: %w", err)
+	}
+	if s.recoveries, err = es.GetActiveRecoveries(); err != nil {
+		return snapshot{}, fmt.Errorf("failed to retrieve recoveries: %w", err)
+	}
+
+	return s, nil
+}
+
+// indexRates returns the rates of every index present in both snapshots.
+func indexRates(previous, current snapshot) map[string]es.OperationRates {
+	elapsed := current.takenAt.Sub(previous.takenAt)
+	rates := make(map[string]es.OperationRates, len(current.indexStats))
+	for index, stats := range current.indexStats {
+		if previousStats, ok := previous.indexStats[index]; ok {
+			rates[index] = es.ComputeRates(previousStats, stats, elapsed)
+		}
+	}
+	return rates
+}
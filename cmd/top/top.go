@@ -0,0 +1,190 @@
+package top
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	enterAltScreen = "\033[?1049h"
+	exitAltScreen  = "\033[?1049l"
+	hideCursor     = "\033[?25l"
+	showCursor     = "\033[?25h"
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Display a live dashboard of the cluster",
+	Long: utils.Trim(`
+Display a live dashboard of the cluster with the health, the nodes with their heap,
+CPU and disk usage, the busiest indices, thread pools with queued or rejected tasks
+and the active recoveries.
+
+Indexing and search rates are computed from the difference between two successive
+samples of the index stats, so they appear from the second refresh on.
+
+Keys:
+  tab          switch between the nodes and the indices
+  up/down, k/j move the selection
+  enter        show the details of the selected node or index
+  esc          go back to the overview
+  s            change the sort order of the focused list
+  /            filter nodes and indices by name
+  r            refresh now
+  q            quit`),
+	Example: utils.TrimAndIndent(`
+# Refresh the dashboard every 5 seconds.
+esctl top --interval 5s`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			fmt.Fprintln(os.Stderr, "The top command requires a terminal.")
+			os.Exit(1)
+		}
+
+		if err := run(); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to run top:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	topCmd.Flags().DurationVar(&flagInterval, "interval", 3*time.Second, "Refresh interval")
+}
+
+func Cmd() *cobra.Command {
+	return topCmd
+}
+
+func run() error {
+	if flagInterval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Print(enterAltScreen + hideCursor)
+	defer fmt.Print(showCursor + exitAltScreen)
+
+	keys := readKeys(os.Stdin)
+	samples := make(chan sample, 1)
+	fetching := false
+	refresh := func() {
+		if fetching {
+			return
+		}
+		fetching = true
+		go func() {
+			s, err := takeSnapshot()
+			samples <- sample{snapshot: s, err: err}
+		}()
+	}
+
+	ticker := time.NewTicker(flagInterval)
+	defer ticker.Stop()
+
+	d := &dashboard{}
+	refresh()
+	for {
+		d.draw(os.Stdout)
+
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			switch d.handleKey(key) {
+			case actionQuit:
+				return nil
+			case actionRefresh:
+				refresh()
+			}
+		case s := <-samples:
+			fetching = false
+			d.update(s)
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
+
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyTab       = "tab"
+	keyBackspace = "backspace"
+	keyCtrlC     = "ctrl-c"
+)
+
+// readKeys reads the raw terminal input and sends every key press as either a named
+// key or the typed character.
+func readKeys(r io.Reader) <-chan string {
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 64)
+		for {
+			n, err := r.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range parseKeys(buf[:n]) {
+				keys <- key
+			}
+		}
+	}()
+	return keys
+}
+
+func parseKeys(input []byte) []string {
+	var keys []string
+	for i := 0; i < len(input); i++ {
+		switch b := input[i]; b {
+		case 0x1b:
+			if i+2 < len(input) && input[i+1] == '[' {
+				switch input[i+2] {
+				case 'A':
+					keys = append(keys, keyUp)
+				case 'B':
+					keys = append(keys, keyDown)
+				case 'C':
+					keys = append(keys, keyRight)
+				case 'D':
+					keys = append(keys, keyLeft)
+				}
+				i += 2
+			} else {
+				keys = append(keys, keyEscape)
+			}
+		case '\r', '\n':
+			keys = append(keys, keyEnter)
+		case '\t':
+			keys = append(keys, keyTab)
+		case 0x7f, 0x08:
+			keys = append(keys, keyBackspace)
+		case 0x03:
+			keys = append(keys, keyCtrlC)
+		default:
+			if b >= 0x20 && b < 0x7f {
+				keys = append(keys, string(b))
+			}
+		}
+	}
+	return keys
+}
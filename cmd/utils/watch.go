@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	clearScreen    = "\033[H\033[2J"
+	highlightStart = "\033[7m"
+	highlightEnd   = "\033[0m"
+)
+
+func normalizeLine(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// Watch redraws the output of render in place every interval. Lines that were not part of
+// the previous output are highlighted. Watching stops when render reports that it is done.
+func Watch(interval time.Duration, render func() (string, bool)) {
+	command := append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...)
+	title := fmt.Sprintf("Every %s: %s", interval, strings.Join(command, " "))
+	var previous map[string]bool
+
+	for {
+		text, done := render()
+
+		header := fmt.Sprintf("%s\t%s\n\n", title, time.Now().Format(time.RFC1123))
+		body, current := highlightChanges(text, previous)
+		fmt.Print(clearScreen + header + body)
+
+		if done {
+			return
+		}
+
+		previous = current
+		time.Sleep(interval)
+	}
+}
+
+// highlightChanges returns the text with the lines that were not part of the previous
+// output highlighted, and the lines of the text to compare the next output with. Nothing
+// is highlighted when there is no previous output. Lines are compared with their
+// whitespace normalized so that realigned columns are not reported as changes.
+func highlightChanges(text string, previous map[string]bool) (string, map[string]bool) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+
+	var body strings.Builder
+	current := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		normalized := normalizeLine(line)
+		current[normalized] = true
+		if previous != nil && normalized != "" && !previous[normalized] {
+			body.WriteString(highlightStart + line + highlightEnd + "\n")
+		} else {
+			body.WriteString(line + "\n")
+		}
+	}
+	return body.String(), current
+}
@@ -0,0 +1,64 @@
+package utils
+
+import "testing"
+
+func TestHighlightChanges(t *testing.T) {
+	highlighted := func(line string) string { return highlightStart + line + highlightEnd + "\n" }
+
+	tests := []struct {
+		name     string
+		previous string
+		text     string
+		expected string
+	}{
+		{
+			name:     "first output",
+			text:     "status: green\nnodes: 3\n",
+			expected: "status: green\nnodes: 3\n",
+		},
+		{
+			name:     "unchanged",
+			previous: "status: green\nnodes: 3",
+			text:     "status: green\nnodes: 3",
+			expected: "status: green\nnodes: 3\n",
+		},
+		{
+			name:     "changed line",
+			previous: "status: green\nnodes: 3",
+			text:     "status: yellow\nnodes: 3",
+			expected: highlighted("status: yellow") + "nodes: 3\n",
+		},
+		{
+			name:     "added line",
+			previous: "status: green",
+			text:     "status: green\nunassigned: 1",
+			expected: "status: green\n" + highlighted("unassigned: 1"),
+		},
+		{
+			name:     "realigned columns",
+			previous: "NAME  STATUS\nlogs  green",
+			text:     "NAME      STATUS\nlogs      green",
+			expected: "NAME      STATUS\nlogs      green\n",
+		},
+		{
+			name:     "blank lines",
+			previous: "status: green",
+			text:     "status: green\n\nnodes: 3",
+			expected: "status: green\n\n" + highlighted("nodes: 3"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var previous map[string]bool
+			if test.previous != "" {
+				_, previous = highlightChanges(test.previous, nil)
+			}
+
+			actual, _ := highlightChanges(test.text, previous)
+			if actual != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
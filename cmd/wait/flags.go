@@ -0,0 +1,13 @@
+package wait
+
+import "time"
+
+var (
+	flagIndex          string
+	flagInterval       time.Duration
+	flagNoInitializing bool
+	flagNoRelocating   bool
+	flagNoUnassigned   bool
+	flagStatus         string
+	flagTimeout        time.Duration
+)
@@ -0,0 +1,170 @@
+package wait
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/cmd/utils"
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/spf13/cobra"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Block until the cluster reaches a given state",
+	Long: utils.Trim(`
+Block until the cluster reaches a given state or the timeout expires.
+
+The exit code is 0 when the state is reached, 2 when the timeout expires and 1
+on any other error, so wait can be used as a step in deploy scripts.`),
+}
+
+var waitHealthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Wait for the cluster or an index to reach a health status",
+	Example: utils.TrimAndIndent(`
+# Wait up to 10 minutes for the cluster to turn green.
+esctl wait health --status green --timeout 10m
+
+# Wait for a single index to turn yellow.
+esctl wait health --index articles --status yellow`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isValidStatus(flagStatus) {
+			fmt.Fprintf(os.Stderr, "Invalid status: %s. Must be one of green, yellow or red.\n", flagStatus)
+			os.Exit(constants.ExitCodeError)
+		}
+
+		health, err := es.WaitForClusterHealth(flagIndex, flagStatus, false, false, false, flagTimeout)
+		exitOnHealth(health, err, fmt.Sprintf("status %s", flagStatus))
+	},
+}
+
+var waitShardsCmd = &cobra.Command{
+	Use:   "shards",
+	Short: "Wait for shards to settle",
+	Long: utils.Trim(`
+Wait until there are no relocating, initializing or unassigned shards. When none
+of the conditions is given, all of them are waited for.`),
+	Example: utils.TrimAndIndent(`
+# Wait for relocations and recoveries to finish.
+esctl wait shards --no-relocating --no-initializing
+
+# Wait for every shard of an index to be started.
+esctl wait shards --index articles`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !flagNoRelocating && !flagNoInitializing && !flagNoUnassigned {
+			flagNoRelocating, flagNoInitializing, flagNoUnassigned = true, true, true
+		}
+
+		var conditions []string
+		if flagNoRelocating {
+			conditions = append(conditions, "no relocating")
+		}
+		if flagNoInitializing {
+			conditions = append(conditions, "no initializing")
+		}
+		if flagNoUnassigned {
+			conditions = append(conditions, "no unassigned")
+		}
+
+		health, err := es.WaitForClusterHealth(flagIndex, "", flagNoRelocating, flagNoInitializing, flagNoUnassigned, flagTimeout)
+		exitOnHealth(health, err, strings.Join(conditions, ", ")+" shards")
+	},
+}
+
+var waitTaskCmd = &cobra.Command{
+	Use:   "task NODE:ID",
+	Short: "Wait for a task to complete",
+	Long: utils.Trim(`
+Wait for a task to complete by polling the tasks API. A task that completes with
+an error exits with code 1.`),
+	Example: utils.TrimAndIndent(`
+# Wait for a reindex task started with wait_for_completion=false.
+esctl wait task oTUltX4IQMOUUVeiohTt8A:12345 --timeout 1h`),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		taskID := args[0]
+		deadline := time.Now().Add(flagTimeout)
+
+		for {
+			status, err := es.GetTask(taskID)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to retrieve task:", err)
+				os.Exit(constants.ExitCodeError)
+			}
+
+			if status.Completed {
+				if status.Error != nil {
+					fmt.Fprintf(os.Stderr, "Task %s failed: %v\n", taskID, status.Error["reason"])
+					os.Exit(constants.ExitCodeError)
+				}
+				fmt.Printf("Task %s completed.\n", taskID)
+				os.Exit(constants.ExitCodeSuccess)
+			}
+
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				fmt.Fprintf(os.Stderr, "Timed out after %s waiting for task %s.\n", flagTimeout, taskID)
+				os.Exit(constants.ExitCodeTimeout)
+			}
+
+			// The last sleep ends at the deadline so that the task is checked once more then.
+			sleep := flagInterval
+			if remaining < sleep {
+				sleep = remaining
+			}
+			time.Sleep(sleep)
+		}
+	},
+}
+
+func init() {
+	waitCmd.PersistentFlags().DurationVar(&flagTimeout, "timeout", 30*time.Second, "Maximum time to wait")
+
+	waitHealthCmd.Flags().StringVar(&flagStatus, "status", "green", "Health status to wait for (green, yellow or red)")
+	waitHealthCmd.Flags().StringVar(&flagIndex, "index", "", "Wait for the health of the given index instead of the cluster")
+
+	waitShardsCmd.Flags().BoolVar(&flagNoRelocating, "no-relocating", false, "Wait until no shards are relocating")
+	waitShardsCmd.Flags().BoolVar(&flagNoInitializing, "no-initializing", false, "Wait until no shards are initializing")
+	waitShardsCmd.Flags().BoolVar(&flagNoUnassigned, "no-unassigned", false, "Wait until all shards are active")
+	waitShardsCmd.Flags().StringVar(&flagIndex, "index", "", "Wait for the shards of the given index instead of the cluster")
+
+	waitTaskCmd.Flags().DurationVar(&flagInterval, "interval", 2*time.Second, "Interval between polls")
+
+	waitCmd.AddCommand(waitHealthCmd)
+	waitCmd.AddCommand(waitShardsCmd)
+	waitCmd.AddCommand(waitTaskCmd)
+}
+
+func Cmd() *cobra.Command {
+	return waitCmd
+}
+
+func isValidStatus(status string) bool {
+	switch status {
+	case "green", "yellow", "red":
+		return true
+	}
+	return false
+}
+
+func exitOnHealth(health es.ClusterHealth, err error, condition string) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to retrieve cluster health:", err)
+		os.Exit(constants.ExitCodeError)
+	}
+
+	if health.TimedOut {
+		fmt.Fprintf(os.Stderr, "Timed out after %s waiting for %s (status: %s, relocating: %d, initializing: %d, unassigned: %d).\n",
+			flagTimeout, condition, health.Status, health.RelocatingShards, health.InitializingShards, health.UnassignedShards)
+		os.Exit(constants.ExitCodeTimeout)
+	}
+
+	fmt.Printf("Cluster %s reached %s (status: %s).\n", health.ClusterName, condition, health.Status)
+	os.Exit(constants.ExitCodeSuccess)
+}
@@ -5,10 +5,14 @@ const (
 	EntityNodes   = "nodes"
 	EntityIndex   = "index"
 	EntityIndices = "indices"
+	EntityShard   = "shard"
 	EntityShards  = "shards"
+	EntityAlias   = "alias"
 	EntityAliases = "aliases"
 	EntityTasks   = "tasks"
 	EntityCluster = "cluster"
+
+	EntityDeprecations = "deprecations"
 )
 
 const (
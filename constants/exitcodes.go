@@ -0,0 +1,8 @@
+package constants
+
+const (
+	ExitCodeSuccess  = 0
+	ExitCodeError    = 1
+	ExitCodeTimeout  = 2
+	ExitCodeCritical = 3
+)
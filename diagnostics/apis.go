@@ -0,0 +1,39 @@
+package diagnostics
+
+// API is an endpoint collected into the bundle.
+type API struct {
+	Name     string
+	Endpoint string
+}
+
+// APIs are the endpoints collected into every bundle. The _cat endpoints request the same
+// columns as esctl get, so that a bundle can be read back by the read commands.
+var APIs = []API{
+	{"cluster_health", "_cluster/health"},
+	{"cluster_state", "_cluster/state"},
+	{"cluster_stats", "_cluster/stats"},
+	{"cluster_settings", "_cluster/settings"},
+	{"cluster_settings_defaults", "_cluster/settings?flat_settings=true&include_defaults=true"},
+	{"cluster_pending_tasks", "_cluster/pending_tasks"},
+	{"allocation_explain", "_cluster/allocation/explain"},
+	{"nodes", "_nodes"},
+	{"nodes_stats", "_nodes/stats"},
+	{"cat_nodes", "_cat/nodes?format=json&h=name,ip,node.role,master,heap.max,heap.current,heap.percent,cpu,load_1m,disk.total,disk.used,disk.avail,ram.current,ram.max,ram.percent,uptime"},
+	{"cat_indices", "_cat/indices?format=json&h=health,status,index,uuid,pri,rep,docs.count,docs.deleted,creation.date.string,store.size,pri.store.size"},
+	{"cat_shards", "_cat/shards?format=json&h=index,shard,prirep,state,docs,store,ip,id,node,unassigned.reason,unassigned.at,segments.count"},
+	{"cat_allocation", "_cat/allocation?format=json"},
+	{"cat_thread_pool", "_cat/thread_pool?format=json&h=node_name,name,active,queue,rejected"},
+	{"cat_recovery", "_cat/recovery?active_only=true&format=json&h=index,shard,type,stage,source_node,target_node,bytes_percent,time"},
+	{"cat_nodeattrs", "_cat/nodeattrs?format=json&h=node,attr,value"},
+	{"aliases", "_all/_alias"},
+	{"index_settings", "_all/_settings"},
+	{"index_settings_flat", "_all/_settings?flat_settings=true"},
+	{"index_mappings", "_all/_mappings"},
+	{"index_stats", "_stats/indexing,search,merge,refresh"},
+	{"tasks", "_tasks?detailed=true"},
+	{"templates", "_template"},
+	{"index_templates", "_index_template"},
+	{"component_templates", "_component_template"},
+	{"ilm_explain", "_all/_ilm/explain"},
+	{"deprecations", "_migration/deprecations"},
+}
@@ -0,0 +1,168 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Write writes the bundle as a gzipped tarball with the manifest and one file per API.
+func (b *Bundle) Write(path string) error {
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	names := make([]string, 0, len(b.Files))
+	for name := range b.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := writeFile(tarWriter, ManifestFile, append(manifest, '\n'), b.Manifest.CollectedAt); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := writeFile(tarWriter, name, b.Files[name], b.Manifest.CollectedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func writeFile(w *tar.Writer, name string, content []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	}
+	if err := w.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := w.Write(content)
+	return err
+}
+
+// Open reads a bundle from a gzipped tarball written by Write or from a directory. A
+// directory or tarball without a manifest is read as saved responses named after the
+// APIs, e.g. cat_shards.json holding the response of _cat/shards.
+func Open(bundlePath string) (*Bundle, error) {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string][]byte
+	if info.IsDir() {
+		files, err = readDir(bundlePath)
+	} else {
+		files, err = readTarball(bundlePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{Files: files}
+	if manifest, ok := files[ManifestFile]; ok {
+		if err := json.Unmarshal(manifest, &bundle.Manifest); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+		}
+		delete(files, ManifestFile)
+		return bundle, nil
+	}
+
+	for _, api := range APIs {
+		file := api.Name + ".json"
+		if _, ok := files[file]; ok {
+			bundle.Manifest.Results = append(bundle.Manifest.Results, Result{
+				Name: api.Name, Endpoint: api.Endpoint, File: file, Status: 200,
+			})
+		}
+	}
+	if len(bundle.Manifest.Results) == 0 {
+		return nil, fmt.Errorf("no %s or saved responses found in %s", ManifestFile, bundlePath)
+	}
+
+	return bundle, nil
+}
+
+func readDir(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = content
+	}
+	return files, nil
+}
+
+func readTarball(tarball string) (map[string][]byte, error) {
+	file, err := os.Open(tarball)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Files are looked up by name, whatever directory the tarball was created from.
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Base(header.Name)] = content
+	}
+}
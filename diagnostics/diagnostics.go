@@ -0,0 +1,134 @@
+package diagnostics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/shared"
+)
+
+// ManifestVersion is the version of the bundle layout.
+const ManifestVersion = 1
+
+// ManifestFile is the name of the manifest in the bundle.
+const ManifestFile = "manifest.json"
+
+// Manifest describes the contents of a bundle.
+type Manifest struct {
+	Version     int       `json:"version"`
+	CollectedAt time.Time `json:"collected_at"`
+	Host        string    `json:"host"`
+	RedactedIPs bool      `json:"redacted_ips"`
+	Results     []Result  `json:"results"`
+}
+
+// Result is the outcome of collecting a single API.
+type Result struct {
+	Name       string `json:"name"`
+	Endpoint   string `json:"endpoint"`
+	File       string `json:"file,omitempty"`
+	Status     int    `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Size       int    `json:"size"`
+	SHA256     string `json:"sha256,omitempty"`
+	Error      string `json:"error,omitempty"`
+
+	body []byte
+}
+
+func (r Result) Failed() bool {
+	return r.Error != ""
+}
+
+// Bundle is a collected set of responses with their manifest.
+type Bundle struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// Collect requests the APIs with at most concurrency requests in flight and redacts the
+// responses. Responses with an error status are kept in the bundle and recorded as failed.
+func Collect(apis []API, concurrency int, redactor *Redactor) *Bundle {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]Result, len(apis))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, api := range apis {
+		wg.Add(1)
+		go func(i int, api API) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results[i] = collect(api)
+		}(i, api)
+	}
+	wg.Wait()
+
+	bundle := &Bundle{
+		Manifest: Manifest{
+			Version:     ManifestVersion,
+			CollectedAt: time.Now().UTC(),
+			Host:        redactor.redactIPs(shared.ElasticsearchHost),
+			RedactedIPs: redactor.RedactIPs,
+		},
+		Files: make(map[string][]byte),
+	}
+
+	// Responses are redacted in the order of the APIs so that IP placeholders are stable.
+	for _, result := range results {
+		if result.body != nil {
+			body := redactor.Redact(result.body)
+			checksum := sha256.Sum256(body)
+
+			result.File = result.Name + ".json"
+			result.Size = len(body)
+			result.SHA256 = hex.EncodeToString(checksum[:])
+			bundle.Files[result.File] = body
+		}
+		bundle.Manifest.Results = append(bundle.Manifest.Results, result)
+	}
+
+	return bundle
+}
+
+func collect(api API) Result {
+	result := Result{Name: api.Name, Endpoint: api.Endpoint}
+
+	start := time.Now()
+	body, status, err := es.GetRaw(api.Endpoint)
+	result.DurationMs = time.Since(start).Milliseconds()
+	result.Status = status
+
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+	case status != http.StatusOK:
+		result.Error = fmt.Sprintf("unexpected http status: %d %s", status, http.StatusText(status))
+	}
+	if len(body) > 0 {
+		result.body = body
+	}
+
+	return result
+}
+
+// Failures returns the number of APIs that could not be collected.
+func (b *Bundle) Failures() int {
+	failures := 0
+	for _, result := range b.Manifest.Results {
+		if result.Failed() {
+			failures++
+		}
+	}
+	return failures
+}
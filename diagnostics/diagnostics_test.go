@@ -0,0 +1,134 @@
+package diagnostics
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/shared"
+)
+
+type stubResponse struct {
+	status int
+	body   string
+}
+
+// stubTransport answers the requests with the responses keyed by path and fails the
+// requests of other paths as if the cluster could not be reached.
+type stubTransport map[string]stubResponse
+
+func (s stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	stub, ok := s[strings.TrimPrefix(req.URL.Path, "/")]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{
+		StatusCode: stub.status,
+		Status:     http.StatusText(stub.status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(stub.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestCollect(t *testing.T) {
+	defer func(protocol, host string, port int) {
+		shared.ElasticsearchProtocol, shared.ElasticsearchHost, shared.ElasticsearchPort = protocol, host, port
+		es.SetTransport(http.DefaultTransport)
+	}(shared.ElasticsearchProtocol, shared.ElasticsearchHost, shared.ElasticsearchPort)
+
+	shared.ElasticsearchProtocol, shared.ElasticsearchHost, shared.ElasticsearchPort = "http", "10.0.0.9", 9200
+	es.SetTransport(stubTransport{
+		"_nodes":                      {200, `{"nodes":{"n1":{"ip":"10.0.0.1","settings":{"s3.client.default.secret_key":"abc"}}}}`},
+		"_cat/nodes":                  {200, `[{"ip":"10.0.0.2"},{"ip":"10.0.0.1"}]`},
+		"_cluster/allocation/explain": {400, `{"error":{"reason":"no unassigned shards on 10.0.0.2"},"status":400}`},
+	})
+
+	apis := []API{
+		{"nodes", "_nodes"},
+		{"cat_nodes", "_cat/nodes?format=json&h=ip"},
+		{"allocation_explain", "_cluster/allocation/explain"},
+		{"tasks", "_tasks"},
+	}
+	bundle := Collect(apis, 2, NewRedactor(true))
+
+	if bundle.Manifest.Host != "ip-1" || !bundle.Manifest.RedactedIPs {
+		t.Errorf("expected the host to be redacted, got %+v", bundle.Manifest)
+	}
+	if failures := bundle.Failures(); failures != 2 {
+		t.Errorf("expected 2 failures, got %d", failures)
+	}
+
+	results := bundle.Manifest.Results
+	if len(results) != len(apis) {
+		t.Fatalf("expected %d results, got %d", len(apis), len(results))
+	}
+	for i, result := range results {
+		if result.Name != apis[i].Name || result.Endpoint != apis[i].Endpoint {
+			t.Errorf("expected the results in the order of the APIs, got %s at %d", result.Name, i)
+		}
+	}
+
+	explain := results[2]
+	if explain.Status != 400 || explain.File != "allocation_explain.json" || explain.Error != "unexpected http status: 400 Bad Request" {
+		t.Errorf("expected the failed response to be kept, got %+v", explain)
+	}
+	tasks := results[3]
+	if tasks.Status != 0 || tasks.File != "" || !strings.Contains(tasks.Error, "connection refused") {
+		t.Errorf("expected the unreachable API to be recorded without a file, got %+v", tasks)
+	}
+	if len(bundle.Files) != 3 {
+		t.Errorf("expected 3 files, got %d", len(bundle.Files))
+	}
+
+	// The size and the checksum are those of the redacted file.
+	for _, result := range results[:3] {
+		body := bundle.Files[result.File]
+		checksum := sha256.Sum256(body)
+		if result.Size != len(body) || result.SHA256 != hex.EncodeToString(checksum[:]) {
+			t.Errorf("%s: expected size %d and checksum %x, got %d and %s", result.Name, len(body), checksum, result.Size, result.SHA256)
+		}
+		if bytes.Contains(body, []byte("10.0.0.")) || bytes.Contains(body, []byte(`"abc"`)) {
+			t.Errorf("%s: expected the file to be redacted, got %s", result.Name, body)
+		}
+	}
+
+	// Every IP gets the same placeholder in every file, numbered in the order of the APIs.
+	expected := map[string]string{
+		"nodes.json":              "{\n  \"nodes\": {\n    \"n1\": {\n      \"ip\": \"ip-2\",\n      \"settings\": {\n        \"s3.client.default.secret_key\": \"[REDACTED]\"\n      }\n    }\n  }\n}\n",
+		"cat_nodes.json":          "[\n  {\n    \"ip\": \"ip-3\"\n  },\n  {\n    \"ip\": \"ip-2\"\n  }\n]\n",
+		"allocation_explain.json": "{\n  \"error\": {\n    \"reason\": \"no unassigned shards on ip-3\"\n  },\n  \"status\": 400\n}\n",
+	}
+	for file, content := range expected {
+		if string(bundle.Files[file]) != content {
+			t.Errorf("%s: expected %q, got %q", file, content, bundle.Files[file])
+		}
+	}
+
+	tarball := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := bundle.Write(tarball); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opened, err := Open(tarball)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	written, _ := json.Marshal(bundle.Manifest)
+	read, _ := json.Marshal(opened.Manifest)
+	if !bytes.Equal(written, read) {
+		t.Errorf("expected manifest %s, got %s", written, read)
+	}
+	if !reflect.DeepEqual(opened.Files, bundle.Files) {
+		t.Errorf("expected files %v, got %v", bundle.Files, opened.Files)
+	}
+}
@@ -0,0 +1,97 @@
+package diagnostics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	// secretKeys match the last segment of the keys whose values are credentials.
+	secretKeys = regexp.MustCompile(`(?i)(password|passwd|secret|token|credentials|authorization|(api|access|secret|private)_?key)$`)
+	ipAddress  = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`)
+)
+
+// Redactor removes credentials, and optionally IP addresses, from the collected responses.
+// IP addresses are replaced consistently across the bundle, so the same address gets the
+// same placeholder in every file. A Redactor is not safe for concurrent use.
+type Redactor struct {
+	RedactIPs bool
+
+	ips map[string]string
+}
+
+func NewRedactor(redactIPs bool) *Redactor {
+	return &Redactor{RedactIPs: redactIPs, ips: make(map[string]string)}
+}
+
+// Redact returns the body with the credentials replaced. JSON bodies are re-indented and
+// their keys sorted; other bodies only have their IP addresses replaced.
+func (r *Redactor) Redact(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []byte(r.redactIPs(string(body)))
+	}
+
+	redactedBody, err := json.MarshalIndent(r.redactValue("", value), "", "  ")
+	if err != nil {
+		return []byte(r.redactIPs(string(body)))
+	}
+	return append(redactedBody, '\n')
+}
+
+func (r *Redactor) redactValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// The keys are walked in order so that the placeholders are numbered the same way
+		// on every run.
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		redactedMap := make(map[string]interface{}, len(v))
+		for _, k := range keys {
+			redactedMap[r.redactIPs(k)] = r.redactValue(k, v[k])
+		}
+		return redactedMap
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.redactValue(key, child)
+		}
+		return v
+	case string:
+		if isSecretKey(key) {
+			return redacted
+		}
+		return r.redactIPs(v)
+	}
+	return value
+}
+
+func isSecretKey(key string) bool {
+	segments := strings.Split(key, ".")
+	return secretKeys.MatchString(segments[len(segments)-1])
+}
+
+func (r *Redactor) redactIPs(s string) string {
+	if !r.RedactIPs {
+		return s
+	}
+
+	return ipAddress.ReplaceAllStringFunc(s, func(ip string) string {
+		if _, ok := r.ips[ip]; !ok {
+			r.ips[ip] = fmt.Sprintf("ip-%d", len(r.ips)+1)
+		}
+		return r.ips[ip]
+	})
+}
@@ -0,0 +1,55 @@
+package diagnostics
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name      string
+		redactIPs bool
+		body      string
+		expected  string
+	}{
+		{
+			name:     "credentials",
+			body:     `{"persistent":{"s3.client.default.secret_key":"abc","xpack.security.authc.realms.ldap.bind_password":"pw","cluster.name":"prod"}}`,
+			expected: "{\n  \"persistent\": {\n    \"cluster.name\": \"prod\",\n    \"s3.client.default.secret_key\": \"[REDACTED]\",\n    \"xpack.security.authc.realms.ldap.bind_password\": \"[REDACTED]\"\n  }\n}\n",
+		},
+		{
+			name:     "nested credentials and numbers",
+			body:     `{"headers":{"Authorization":"Basic Zm9vOmJhcg=="},"size":12345678901234567890}`,
+			expected: "{\n  \"headers\": {\n    \"Authorization\": \"[REDACTED]\"\n  },\n  \"size\": 12345678901234567890\n}\n",
+		},
+		{
+			name:     "ips kept",
+			body:     `[{"ip":"10.0.0.1"}]`,
+			expected: "[\n  {\n    \"ip\": \"10.0.0.1\"\n  }\n]\n",
+		},
+		{
+			name:      "ips redacted",
+			redactIPs: true,
+			body:      `{"nodes":{"10.0.0.1:9300":{"ip":"10.0.0.1","publish":"10.0.0.2:9200"}}}`,
+			expected:  "{\n  \"nodes\": {\n    \"ip-1:9300\": {\n      \"ip\": \"ip-1\",\n      \"publish\": \"ip-2:9200\"\n    }\n  }\n}\n",
+		},
+		{
+			name:      "ips numbered in key order",
+			redactIPs: true,
+			body:      `{"publish":"10.0.0.3","bound":"10.0.0.2","address":"10.0.0.1","10.0.0.4:9300":{"host":"10.0.0.2"}}`,
+			expected:  "{\n  \"address\": \"ip-3\",\n  \"bound\": \"ip-2\",\n  \"ip-1:9300\": {\n    \"host\": \"ip-2\"\n  },\n  \"publish\": \"ip-4\"\n}\n",
+		},
+		{
+			name:      "plain text",
+			redactIPs: true,
+			body:      "node 10.0.0.1 not found",
+			expected:  "node ip-1 not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			redactor := NewRedactor(test.redactIPs)
+			if actual := string(redactor.Redact([]byte(test.body))); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
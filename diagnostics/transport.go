@@ -0,0 +1,253 @@
+package diagnostics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	catPattern   = regexp.MustCompile(`^_cat/(indices|shards)/([^/]+)$`)
+	indexPattern = regexp.MustCompile(`^([^_/][^/]*)/(_alias|_settings|_mappings)$`)
+)
+
+// Transport serves the responses recorded in a bundle instead of calling the cluster.
+// Requests are matched on their endpoint first. A response recorded with another query is
+// only served when the queries differ in the format or in the _cat columns, and the
+// recorded columns include the requested ones. Index scoped requests, e.g.
+// _cat/shards/logs-* or articles/_mappings, are served by filtering the responses recorded
+// for all indices.
+type Transport struct {
+	bundle    *Bundle
+	endpoints map[string]Result
+	paths     map[string][]Result
+}
+
+func NewTransport(bundle *Bundle) *Transport {
+	t := &Transport{
+		bundle:    bundle,
+		endpoints: make(map[string]Result),
+		paths:     make(map[string][]Result),
+	}
+
+	for _, result := range bundle.Manifest.Results {
+		if result.File == "" {
+			continue
+		}
+		endpointPath, query := normalize(result.Endpoint)
+		t.endpoints[endpointPath+"?"+query] = result
+		t.paths[endpointPath] = append(t.paths[endpointPath], result)
+	}
+
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return errorResponse(req, http.StatusMethodNotAllowed, "bundles are read-only, %s requests are not supported", req.Method), nil
+	}
+
+	endpointPath, query := normalize(req.URL.Path + "?" + req.URL.RawQuery)
+
+	if result, ok := t.lookup(query, endpointPath); ok {
+		return t.respond(req, result, nil)
+	}
+
+	if match := catPattern.FindStringSubmatch(endpointPath); match != nil {
+		if result, ok := t.lookup(query, "_cat/"+match[1]); ok {
+			return t.respond(req, result, filterRows(match[2]))
+		}
+	}
+
+	if match := indexPattern.FindStringSubmatch(endpointPath); match != nil {
+		if result, ok := t.lookup(query, "_all/"+match[2], match[2]); ok {
+			return t.respond(req, result, filterKeys(match[1]))
+		}
+	}
+
+	return errorResponse(req, http.StatusNotFound, "%s is not in the bundle", strings.TrimPrefix(req.URL.RequestURI(), "/")), nil
+}
+
+// lookup returns the result recorded for the first of the paths with the same query, or
+// else with a query answering the same request.
+func (t *Transport) lookup(query string, endpointPaths ...string) (Result, bool) {
+	for _, endpointPath := range endpointPaths {
+		if result, ok := t.endpoints[endpointPath+"?"+query]; ok {
+			return result, true
+		}
+	}
+	for _, endpointPath := range endpointPaths {
+		for _, result := range t.paths[endpointPath] {
+			if _, recorded := normalize(result.Endpoint); answers(recorded, query) {
+				return result, true
+			}
+		}
+	}
+	return Result{}, false
+}
+
+// answers reports whether the response recorded for a query answers a request with
+// another query. The format is ignored, since the responses are always recorded as JSON,
+// and the recorded _cat columns must include the requested ones. Any other parameter may
+// change the response and must be the same.
+func answers(recorded, requested string) bool {
+	recordedQuery, err := url.ParseQuery(recorded)
+	if err != nil {
+		return false
+	}
+	requestedQuery, err := url.ParseQuery(requested)
+	if err != nil {
+		return false
+	}
+
+	recordedColumns := recordedQuery.Get("h")
+	requestedColumns := requestedQuery.Get("h")
+	for _, query := range []url.Values{recordedQuery, requestedQuery} {
+		query.Del("format")
+		query.Del("h")
+	}
+	if recordedQuery.Encode() != requestedQuery.Encode() {
+		return false
+	}
+
+	if requestedColumns == "" || recordedColumns == "" {
+		return requestedColumns == recordedColumns
+	}
+	columns := make(map[string]bool)
+	for _, column := range strings.Split(recordedColumns, ",") {
+		columns[column] = true
+	}
+	for _, column := range strings.Split(requestedColumns, ",") {
+		if !columns[column] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Transport) respond(req *http.Request, result Result, filter func(interface{}) interface{}) (*http.Response, error) {
+	body, ok := t.bundle.Files[result.File]
+	if !ok {
+		return errorResponse(req, http.StatusNotFound, "%s is missing from the bundle", result.File), nil
+	}
+
+	if filter != nil && result.Status == http.StatusOK {
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", result.File, err)
+		}
+
+		filtered, err := json.Marshal(filter(value))
+		if err != nil {
+			return nil, err
+		}
+		body = filtered
+	}
+
+	status := result.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return response(req, status, body), nil
+}
+
+// normalize returns the path of the endpoint without surrounding slashes and its query
+// with the parameters sorted.
+func normalize(endpoint string) (string, string) {
+	endpointPath, rawQuery, _ := strings.Cut(endpoint, "?")
+	endpointPath = strings.Trim(path.Clean("/"+endpointPath), "/")
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return endpointPath, rawQuery
+	}
+	return endpointPath, query.Encode()
+}
+
+// filterRows keeps the _cat rows of the indices matching the pattern.
+func filterRows(pattern string) func(interface{}) interface{} {
+	return func(value interface{}) interface{} {
+		rows, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+
+		filtered := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			if fields, ok := row.(map[string]interface{}); ok {
+				if index, ok := fields["index"].(string); ok && matchIndex(pattern, index) {
+					filtered = append(filtered, row)
+				}
+			}
+		}
+		return filtered
+	}
+}
+
+// filterKeys keeps the entries of the indices matching the pattern in a response keyed
+// by index name.
+func filterKeys(pattern string) func(interface{}) interface{} {
+	return func(value interface{}) interface{} {
+		indices, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+
+		filtered := make(map[string]interface{})
+		for index, entry := range indices {
+			if matchIndex(pattern, index) {
+				filtered[index] = entry
+			}
+		}
+		return filtered
+	}
+}
+
+// matchIndex reports whether the index matches a comma separated list of index names and
+// wildcard patterns.
+func matchIndex(pattern, index string) bool {
+	for _, p := range strings.Split(pattern, ",") {
+		if p == "_all" {
+			return true
+		}
+		if matched, _ := path.Match(p, index); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func response(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// errorResponse returns a response shaped like an Elasticsearch error, so that the reason
+// is reported by the commands.
+func errorResponse(req *http.Request, status int, format string, args ...interface{}) *http.Response {
+	body, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]interface{}{
+			"type":   "bundle_exception",
+			"reason": fmt.Sprintf(format, args...),
+		},
+		"status": status,
+	})
+	return response(req, status, body)
+}
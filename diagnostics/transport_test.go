@@ -0,0 +1,124 @@
+package diagnostics
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testBundle() *Bundle {
+	return &Bundle{
+		Manifest: Manifest{
+			Version: ManifestVersion,
+			Results: []Result{
+				{Name: "cluster_health", Endpoint: "_cluster/health", File: "cluster_health.json", Status: 200},
+				{Name: "cat_shards", Endpoint: "_cat/shards?format=json&h=index,shard", File: "cat_shards.json", Status: 200},
+				{Name: "index_settings", Endpoint: "_all/_settings", File: "index_settings.json", Status: 200},
+				{Name: "index_settings_flat", Endpoint: "_settings?flat_settings=true", File: "index_settings_flat.json", Status: 200},
+				{Name: "allocation_explain", Endpoint: "_cluster/allocation/explain", File: "allocation_explain.json", Status: 400, Error: "unexpected http status: 400 Bad Request"},
+				{Name: "tasks", Endpoint: "_tasks", Status: 0, Error: "connection refused"},
+			},
+		},
+		Files: map[string][]byte{
+			"cluster_health.json":      []byte(`{"status":"green"}`),
+			"cat_shards.json":          []byte(`[{"index":"logs-1","shard":"0"},{"index":"logs-2","shard":"0"},{"index":"articles","shard":"0"}]`),
+			"index_settings.json":      []byte(`{"articles":{"settings":{"index":{"number_of_shards":"1"}}},"logs-1":{"settings":{}}}`),
+			"index_settings_flat.json": []byte(`{"articles":{"settings":{"index.number_of_shards":"1"}},"logs-1":{"settings":{}}}`),
+			"allocation_explain.json":  []byte(`{"error":{"reason":"no unassigned shards"},"status":400}`),
+		},
+	}
+}
+
+func TestTransport(t *testing.T) {
+	client := &http.Client{Transport: NewTransport(testBundle())}
+
+	tests := []struct {
+		name     string
+		method   string
+		endpoint string
+		status   int
+		expected string
+	}{
+		{"exact", http.MethodGet, "_cluster/health", 200, `{"status":"green"}`},
+		{"query order", http.MethodGet, "_cat/shards?h=index,shard&format=json", 200, `[{"index":"logs-1","shard":"0"},{"index":"logs-2","shard":"0"},{"index":"articles","shard":"0"}]`},
+		{"format", http.MethodGet, "_cluster/health?format=json", 200, `{"status":"green"}`},
+		{"column subset", http.MethodGet, "_cat/shards?format=json&h=shard", 200, `[{"index":"logs-1","shard":"0"},{"index":"logs-2","shard":"0"},{"index":"articles","shard":"0"}]`},
+		{"column superset", http.MethodGet, "_cat/shards?h=index,shard,state", 404, `{"error":{"reason":"_cat/shards?h=index,shard,state is not in the bundle","type":"bundle_exception"},"status":404}`},
+		{"default columns", http.MethodGet, "_cat/shards?format=json", 404, `{"error":{"reason":"_cat/shards?format=json is not in the bundle","type":"bundle_exception"},"status":404}`},
+		{"other parameter", http.MethodGet, "_cluster/health?level=indices", 404, `{"error":{"reason":"_cluster/health?level=indices is not in the bundle","type":"bundle_exception"},"status":404}`},
+		{"leading slash", http.MethodGet, "/_settings?flat_settings=true", 200, `{"articles":{"settings":{"index.number_of_shards":"1"}},"logs-1":{"settings":{}}}`},
+		{"cat pattern", http.MethodGet, "_cat/shards/logs-*?format=json&h=index,shard", 200, `[{"index":"logs-1","shard":"0"},{"index":"logs-2","shard":"0"}]`},
+		{"cat list", http.MethodGet, "_cat/shards/articles,logs-2?format=json&h=index", 200, `[{"index":"logs-2","shard":"0"},{"index":"articles","shard":"0"}]`},
+		{"index settings", http.MethodGet, "articles/_settings", 200, `{"articles":{"settings":{"index":{"number_of_shards":"1"}}}}`},
+		{"index flat settings", http.MethodGet, "articles/_settings?flat_settings=true", 200, `{"articles":{"settings":{"index.number_of_shards":"1"}}}`},
+		{"recorded failure", http.MethodGet, "_cluster/allocation/explain", 400, `{"error":{"reason":"no unassigned shards"},"status":400}`},
+		{"not collected", http.MethodGet, "_tasks", 404, `{"error":{"reason":"_tasks is not in the bundle","type":"bundle_exception"},"status":404}`},
+		{"missing", http.MethodGet, "_nodes/stats", 404, `{"error":{"reason":"_nodes/stats is not in the bundle","type":"bundle_exception"},"status":404}`},
+		{"write", http.MethodPost, "articles/_refresh", 405, `{"error":{"reason":"bundles are read-only, POST requests are not supported","type":"bundle_exception"},"status":405}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, "http://bundle:9200/"+test.endpoint, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != test.status {
+				t.Errorf("expected status %d, got %d", test.status, resp.StatusCode)
+			}
+			if string(body) != test.expected {
+				t.Errorf("expected body %s, got %s", test.expected, body)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	tarball := filepath.Join(dir, "bundle.tar.gz")
+	if err := testBundle().Write(tarball); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bundle, err := Open(tarball)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bundle.Manifest.Results) != 6 || len(bundle.Files) != 5 {
+		t.Errorf("expected 6 results and 5 files, got %d and %d", len(bundle.Manifest.Results), len(bundle.Files))
+	}
+
+	saved := filepath.Join(dir, "saved")
+	if err := os.Mkdir(saved, 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(saved, "cat_indices.json"), []byte(`[]`), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bundle, err = Open(saved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bundle.Manifest.Results) != 1 || bundle.Manifest.Results[0].Name != "cat_indices" {
+		t.Errorf("expected the saved cat_indices response, got %+v", bundle.Manifest.Results)
+	}
+
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Errorf("expected an error for an empty directory")
+	}
+}
@@ -0,0 +1,298 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+)
+
+type ChangeType string
+
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+)
+
+const (
+	CategoryNodes           = "nodes"
+	CategoryIndices         = "indices"
+	CategoryAliases         = "aliases"
+	CategoryShards          = "shards"
+	CategoryClusterSettings = "cluster settings"
+	CategoryIndexSettings   = "index settings"
+	CategoryMappings        = "mappings"
+)
+
+// Categories are the categories of the changes in the order they are reported.
+var Categories = []string{
+	CategoryNodes,
+	CategoryIndices,
+	CategoryAliases,
+	CategoryShards,
+	CategoryClusterSettings,
+	CategoryIndexSettings,
+	CategoryMappings,
+}
+
+// VolatileSettings are the index settings that differ between any two indices and are not
+// reported.
+var VolatileSettings = []string{
+	"index.uuid",
+	"index.creation_date",
+	"index.creation_date_string",
+	"index.provided_name",
+	"index.version.created",
+	"index.version.upgraded",
+	"index.history.uuid",
+	"index.resize.source.uuid",
+	"index.resize.source.name",
+}
+
+// Change is a difference between two states. Field is set when a single property of the
+// entity changed.
+type Change struct {
+	Category string     `json:"category"`
+	Type     ChangeType `json:"type"`
+	Name     string     `json:"name"`
+	Field    string     `json:"field,omitempty"`
+	Old      string     `json:"old,omitempty"`
+	New      string     `json:"new,omitempty"`
+}
+
+// Compare returns the changes from the old state to the new one, sorted by category and
+// name.
+func Compare(old, new *State) []Change {
+	var changes []Change
+
+	changes = append(changes, compareNodes(old.Nodes, new.Nodes)...)
+	changes = append(changes, compareIndices(old.Indices, new.Indices)...)
+	changes = append(changes, compareValues(CategoryAliases, aliasIndices(old.Aliases), aliasIndices(new.Aliases), nil)...)
+
+	indices := commonIndices(old.Indices, new.Indices)
+	changes = append(changes, compareShards(old.Shards, new.Shards, indices)...)
+	changes = append(changes, compareValues(CategoryClusterSettings, explicitSettings(old.ClusterSettings), explicitSettings(new.ClusterSettings), nil)...)
+
+	for index := range indices {
+		changes = append(changes, CompareFlattened(CategoryIndexSettings, index, flattenSettings(old.IndexSettings[index]), flattenSettings(new.IndexSettings[index]), VolatileSettings)...)
+		changes = append(changes, CompareFlattened(CategoryMappings, index, flattenObject(old.Mappings[index]), flattenObject(new.Mappings[index]), nil)...)
+	}
+
+	Sort(changes)
+	return changes
+}
+
+// CompareIndex returns the differences between the flattened settings and mappings of two
+// indices, one change per setting or mapping path. Volatile settings are ignored unless
+// includeVolatile is set.
+func CompareIndex(old, new es.IndexDetails, includeVolatile bool) []Change {
+	ignore := VolatileSettings
+	if includeVolatile {
+		ignore = nil
+	}
+
+	changes := compareValues(CategoryIndexSettings, flattenObject(old.Settings), flattenObject(new.Settings), ignore)
+	changes = append(changes, compareValues(CategoryMappings, flattenObject(old.Mappings), flattenObject(new.Mappings), nil)...)
+
+	Sort(changes)
+	return changes
+}
+
+// CompareFlattened returns the changes between two flattened objects of the named entity,
+// with one change per key. Keys in ignore are skipped.
+func CompareFlattened(category, name string, old, new map[string]string, ignore []string) []Change {
+	ignored := make(map[string]bool, len(ignore))
+	for _, key := range ignore {
+		ignored[key] = true
+	}
+
+	var changes []Change
+	for _, key := range output.FlattenedKeys([]map[string]string{old, new}) {
+		if ignored[key] {
+			continue
+		}
+
+		oldValue, inOld := old[key]
+		newValue, inNew := new[key]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Category: category, Type: Added, Name: name, Field: key, New: newValue})
+		case !inNew:
+			changes = append(changes, Change{Category: category, Type: Removed, Name: name, Field: key, Old: oldValue})
+		case oldValue != newValue:
+			changes = append(changes, Change{Category: category, Type: Changed, Name: name, Field: key, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// Sort sorts the changes by category, name and field.
+func Sort(changes []Change) {
+	rank := make(map[string]int, len(Categories))
+	for i, category := range Categories {
+		rank[category] = i
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		left, right := changes[i], changes[j]
+		if left.Category != right.Category {
+			return rank[left.Category] < rank[right.Category]
+		}
+		if left.Name != right.Name {
+			return left.Name < right.Name
+		}
+		return left.Field < right.Field
+	})
+}
+
+func compareNodes(old, new []es.Node) []Change {
+	return compareEntities(CategoryNodes, nodeFields(old), nodeFields(new))
+}
+
+func nodeFields(nodes []es.Node) map[string]map[string]string {
+	fields := make(map[string]map[string]string, len(nodes))
+	for _, node := range nodes {
+		fields[node.Name] = map[string]string{
+			"ip":     node.IP,
+			"role":   node.NodeRole,
+			"master": node.Master,
+		}
+	}
+	return fields
+}
+
+func compareIndices(old, new []es.Index) []Change {
+	return compareEntities(CategoryIndices, indexFields(old), indexFields(new))
+}
+
+func indexFields(indices []es.Index) map[string]map[string]string {
+	fields := make(map[string]map[string]string, len(indices))
+	for _, index := range indices {
+		fields[index.Index] = map[string]string{
+			"health":   index.Health,
+			"status":   index.Status,
+			"shards":   index.Pri,
+			"replicas": index.Rep,
+			"uuid":     index.UUID,
+		}
+	}
+	return fields
+}
+
+// compareEntities reports the entities that were added or removed, and a change for every
+// field of the entities that exist in both.
+func compareEntities(category string, old, new map[string]map[string]string) []Change {
+	var changes []Change
+	for name, oldFields := range old {
+		newFields, ok := new[name]
+		if !ok {
+			changes = append(changes, Change{Category: category, Type: Removed, Name: name})
+			continue
+		}
+		changes = append(changes, CompareFlattened(category, name, oldFields, newFields, nil)...)
+	}
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			changes = append(changes, Change{Category: category, Type: Added, Name: name})
+		}
+	}
+	return changes
+}
+
+// compareValues compares two maps of names to values. Names in ignore are skipped.
+func compareValues(category string, old, new map[string]string, ignore []string) []Change {
+	var changes []Change
+	for _, change := range CompareFlattened(category, "", old, new, ignore) {
+		change.Name, change.Field = change.Field, ""
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func commonIndices(old, new []es.Index) map[string]bool {
+	inOld := make(map[string]bool, len(old))
+	for _, index := range old {
+		inOld[index.Index] = true
+	}
+
+	common := make(map[string]bool)
+	for _, index := range new {
+		if inOld[index.Index] {
+			common[index.Index] = true
+		}
+	}
+	return common
+}
+
+// compareShards reports the copies of the shards of the given indices that moved to other
+// nodes or changed state. Shards of created or deleted indices are not reported.
+func compareShards(old, new []es.Shard, indices map[string]bool) []Change {
+	return compareValues(CategoryShards, shardLocations(old, indices), shardLocations(new, indices), nil)
+}
+
+// shardLocations returns the nodes holding the copies of every shard. Copies that are not
+// started are reported with their state.
+func shardLocations(shards []es.Shard, indices map[string]bool) map[string]string {
+	copies := make(map[string][]string)
+	for _, shard := range shards {
+		if !indices[shard.Index] {
+			continue
+		}
+
+		location := shard.Node
+		switch {
+		case location == "":
+			location = shard.State
+		case shard.State != "STARTED":
+			location = fmt.Sprintf("%s (%s)", location, shard.State)
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", shard.Index, shard.Shard, shard.PriRep)
+		copies[key] = append(copies[key], location)
+	}
+
+	locations := make(map[string]string, len(copies))
+	for key, nodes := range copies {
+		sort.Strings(nodes)
+		locations[key] = strings.Join(nodes, ", ")
+	}
+	return locations
+}
+
+// aliasIndices returns the indices of every alias as a single value.
+func aliasIndices(aliases map[string][]string) map[string]string {
+	indices := make(map[string]string, len(aliases))
+	for alias, names := range aliases {
+		indices[alias] = strings.Join(names, ", ")
+	}
+	return indices
+}
+
+// explicitSettings returns the cluster settings that are set, ignoring the defaults.
+func explicitSettings(settings es.FlatClusterSettings) map[string]string {
+	explicit := make(map[string]string)
+	for _, values := range []map[string]interface{}{settings.Persistent, settings.Transient} {
+		for key := range values {
+			explicit[key], _ = settings.Get(key)
+		}
+	}
+	return explicit
+}
+
+func flattenSettings(settings map[string]interface{}) map[string]string {
+	flattened := make(map[string]string, len(settings))
+	for key, value := range settings {
+		flattened[key] = fmt.Sprint(value)
+	}
+	return flattened
+}
+
+func flattenObject(object interface{}) map[string]string {
+	if m, ok := object.(map[string]interface{}); ok {
+		return output.Flatten(m)
+	}
+	return map[string]string{}
+}
@@ -0,0 +1,135 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fehmicansaglam/esctl/es"
+)
+
+func TestCompare(t *testing.T) {
+	old := &State{
+		Nodes: []es.Node{
+			{Name: "es-0", IP: "10.0.0.1", NodeRole: "dim", Master: "*"},
+			{Name: "es-1", IP: "10.0.0.2", NodeRole: "dim", Master: "-"},
+		},
+		Indices: []es.Index{
+			{Index: "articles", UUID: "a1", Health: "green", Status: "open", Pri: "1", Rep: "1"},
+			{Index: "logs-1", UUID: "l1", Health: "green", Status: "open", Pri: "1", Rep: "0"},
+		},
+		Shards: []es.Shard{
+			{Index: "articles", Shard: "0", PriRep: "p", State: "STARTED", Node: "es-0"},
+			{Index: "articles", Shard: "0", PriRep: "r", State: "STARTED", Node: "es-1"},
+			{Index: "logs-1", Shard: "0", PriRep: "p", State: "STARTED", Node: "es-1"},
+		},
+		Aliases: map[string][]string{"search": {"articles"}, "logs": {"logs-1"}, "all-logs": {"logs-0", "logs-1"}},
+		ClusterSettings: es.FlatClusterSettings{
+			Persistent: map[string]interface{}{"cluster.routing.allocation.enable": "all"},
+			Defaults:   map[string]interface{}{"cluster.max_shards_per_node": "1000"},
+		},
+		IndexSettings: map[string]map[string]interface{}{
+			"articles": {"index.number_of_replicas": "1", "index.uuid": "a1"},
+		},
+		Mappings: map[string]interface{}{
+			"articles": map[string]interface{}{"properties": map[string]interface{}{
+				"title": map[string]interface{}{"type": "text"},
+			}},
+		},
+	}
+
+	new := &State{
+		Nodes: []es.Node{
+			{Name: "es-0", IP: "10.0.0.1", NodeRole: "dim", Master: "-"},
+			{Name: "es-2", IP: "10.0.0.3", NodeRole: "dim", Master: "*"},
+		},
+		Indices: []es.Index{
+			{Index: "articles", UUID: "a1", Health: "yellow", Status: "open", Pri: "1", Rep: "1"},
+			{Index: "logs-2", UUID: "l2", Health: "green", Status: "open", Pri: "1", Rep: "0"},
+		},
+		Shards: []es.Shard{
+			{Index: "articles", Shard: "0", PriRep: "p", State: "STARTED", Node: "es-2"},
+			{Index: "articles", Shard: "0", PriRep: "r", State: "UNASSIGNED"},
+			{Index: "logs-2", Shard: "0", PriRep: "p", State: "STARTED", Node: "es-0"},
+		},
+		Aliases: map[string][]string{"search": {"articles"}, "logs": {"logs-2"}, "all-logs": {"logs-0", "logs-1", "logs-2"}},
+		ClusterSettings: es.FlatClusterSettings{
+			Persistent: map[string]interface{}{"cluster.routing.allocation.enable": "all"},
+			Transient:  map[string]interface{}{"cluster.routing.allocation.enable": "primaries"},
+			Defaults:   map[string]interface{}{"cluster.max_shards_per_node": "2000"},
+		},
+		IndexSettings: map[string]map[string]interface{}{
+			"articles": {"index.number_of_replicas": "2", "index.uuid": "a2"},
+		},
+		Mappings: map[string]interface{}{
+			"articles": map[string]interface{}{"properties": map[string]interface{}{
+				"title": map[string]interface{}{"type": "keyword"},
+				"tags":  map[string]interface{}{"type": "keyword"},
+			}},
+		},
+	}
+
+	expected := []Change{
+		{Category: CategoryNodes, Type: Changed, Name: "es-0", Field: "master", Old: "*", New: "-"},
+		{Category: CategoryNodes, Type: Removed, Name: "es-1"},
+		{Category: CategoryNodes, Type: Added, Name: "es-2"},
+		{Category: CategoryIndices, Type: Changed, Name: "articles", Field: "health", Old: "green", New: "yellow"},
+		{Category: CategoryIndices, Type: Removed, Name: "logs-1"},
+		{Category: CategoryIndices, Type: Added, Name: "logs-2"},
+		{Category: CategoryAliases, Type: Changed, Name: "all-logs", Old: "logs-0, logs-1", New: "logs-0, logs-1, logs-2"},
+		{Category: CategoryAliases, Type: Changed, Name: "logs", Old: "logs-1", New: "logs-2"},
+		{Category: CategoryShards, Type: Changed, Name: "articles/0/p", Old: "es-0", New: "es-2"},
+		{Category: CategoryShards, Type: Changed, Name: "articles/0/r", Old: "es-1", New: "UNASSIGNED"},
+		{Category: CategoryClusterSettings, Type: Changed, Name: "cluster.routing.allocation.enable", Old: "all", New: "primaries"},
+		{Category: CategoryIndexSettings, Type: Changed, Name: "articles", Field: "index.number_of_replicas", Old: "1", New: "2"},
+		{Category: CategoryMappings, Type: Added, Name: "articles", Field: "properties.tags.type", New: "keyword"},
+		{Category: CategoryMappings, Type: Changed, Name: "articles", Field: "properties.title.type", Old: "text", New: "keyword"},
+	}
+
+	if changes := Compare(old, new); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected changes\n%+v\ngot\n%+v", expected, changes)
+	}
+
+	if changes := Compare(old, old); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestCompareIndex(t *testing.T) {
+	old := es.IndexDetails{
+		Settings: map[string]interface{}{"index": map[string]interface{}{
+			"uuid":             "a1",
+			"provided_name":    "orders-v2",
+			"number_of_shards": "1",
+			"refresh_interval": "1s",
+		}},
+		Mappings: map[string]interface{}{"properties": map[string]interface{}{
+			"id":    map[string]interface{}{"type": "keyword"},
+			"price": map[string]interface{}{"type": "float"},
+		}},
+	}
+	new := es.IndexDetails{
+		Settings: map[string]interface{}{"index": map[string]interface{}{
+			"uuid":             "b2",
+			"provided_name":    "orders-v3",
+			"number_of_shards": "3",
+		}},
+		Mappings: map[string]interface{}{"properties": map[string]interface{}{
+			"id":    map[string]interface{}{"type": "keyword"},
+			"price": map[string]interface{}{"type": "scaled_float", "scaling_factor": float64(100)},
+		}},
+	}
+
+	expected := []Change{
+		{Category: CategoryIndexSettings, Type: Changed, Name: "index.number_of_shards", Old: "1", New: "3"},
+		{Category: CategoryIndexSettings, Type: Removed, Name: "index.refresh_interval", Old: "1s"},
+		{Category: CategoryMappings, Type: Added, Name: "properties.price.scaling_factor", New: "100"},
+		{Category: CategoryMappings, Type: Changed, Name: "properties.price.type", Old: "float", New: "scaled_float"},
+	}
+	if changes := CompareIndex(old, new, false); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected changes\n%+v\ngot\n%+v", expected, changes)
+	}
+
+	if changes := CompareIndex(old, new, true); len(changes) != len(expected)+2 {
+		t.Errorf("expected the volatile settings to be reported, got %+v", changes)
+	}
+}
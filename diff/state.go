@@ -0,0 +1,49 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/fehmicansaglam/esctl/es"
+)
+
+// State is the part of the cluster state that is compared.
+type State struct {
+	Nodes           []es.Node
+	Indices         []es.Index
+	Shards          []es.Shard
+	Aliases         map[string][]string
+	ClusterSettings es.FlatClusterSettings
+	IndexSettings   map[string]map[string]interface{}
+	Mappings        map[string]interface{}
+}
+
+// Collect fetches the state from the cluster, or from the bundle the requests are served
+// from.
+func Collect() (*State, error) {
+	var state State
+	var err error
+
+	if state.Nodes, err = es.GetNodes(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve nodes: %w", err)
+	}
+	if state.Indices, err = es.GetIndices(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve indices: %w", err)
+	}
+	if state.Shards, err = es.GetShards(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve shards: %w", err)
+	}
+	if state.Aliases, err = es.GetAliasIndices(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve aliases: %w", err)
+	}
+	if state.ClusterSettings, err = es.GetFlatClusterSettings(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve cluster settings: %w", err)
+	}
+	if state.IndexSettings, err = es.GetFlatIndexSettings(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve index settings: %w", err)
+	}
+	if state.Mappings, err = es.GetMappings(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve mappings: %w", err)
+	}
+
+	return &state, nil
+}
@@ -0,0 +1,148 @@
+package doctor
+
+import (
+	"fmt"
+
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/entities"
+)
+
+// RuleDefinition declares a custom rule in esctl.yml or in a rules file. The assertion is
+// evaluated for every row of the entity that matches the filter, and every row for which
+// it does not hold is reported.
+type RuleDefinition struct {
+	Name     string `mapstructure:"name"`
+	Entity   string `mapstructure:"entity"`
+	Filter   string `mapstructure:"filter"`
+	Assert   string `mapstructure:"assert"`
+	Severity string `mapstructure:"severity"`
+	Message  string `mapstructure:"message"`
+	Hint     string `mapstructure:"hint"`
+}
+
+// nameColumns are the columns identifying a row of every entity custom rules support.
+var nameColumns = map[string][]string{
+	constants.EntityIndex: {"INDEX"},
+	constants.EntityNode:  {"NAME"},
+	constants.EntityShard: {"INDEX", "SHARD", "PRI-REP"},
+	constants.EntityAlias: {"ALIAS"},
+}
+
+// NewCustomRule compiles the definition into a rule evaluated over Snapshot.Rows.
+func NewCustomRule(definition RuleDefinition) (Rule, error) {
+	if definition.Name == "" {
+		return Rule{}, fmt.Errorf("rule without a name")
+	}
+	if _, ok := nameColumns[definition.Entity]; !ok {
+		return Rule{}, fmt.Errorf("rule %s: unknown entity %q, must be one of index, node, shard or alias", definition.Name, definition.Entity)
+	}
+	if definition.Assert == "" {
+		return Rule{}, fmt.Errorf("rule %s: assert is required", definition.Name)
+	}
+
+	severity := Severity(definition.Severity)
+	switch severity {
+	case "":
+		severity = SeverityWarning
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+	default:
+		return Rule{}, fmt.Errorf("rule %s: unknown severity %q, must be one of info, warning or critical", definition.Name, definition.Severity)
+	}
+
+	var filter *Expression
+	if definition.Filter != "" {
+		var err error
+		if filter, err = ParseExpression(definition.Filter); err != nil {
+			return Rule{}, fmt.Errorf("rule %s: invalid filter: %w", definition.Name, err)
+		}
+	}
+
+	assert, err := ParseExpression(definition.Assert)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %s: invalid assert: %w", definition.Name, err)
+	}
+
+	for _, expression := range []*Expression{filter, assert} {
+		if err := checkColumns(definition, expression); err != nil {
+			return Rule{}, err
+		}
+	}
+
+	message := definition.Message
+	if message == "" {
+		message = "Assertion failed: " + definition.Assert
+	}
+
+	check := func(snapshot *Snapshot) []Finding {
+		var findings []Finding
+		for _, row := range snapshot.Rows[definition.Entity] {
+			ok, err := evaluateRule(filter, assert, row)
+			if err != nil {
+				// The columns are checked when the rule is compiled, so this is not a problem of
+				// the cluster and is not reported as critical.
+				return []Finding{{
+					Severity: SeverityWarning,
+					Entity:   "rule",
+					Name:     definition.Name,
+					Message:  fmt.Sprintf("Failed to evaluate the rule: %v", err),
+					Hint:     "Check the column names with 'esctl get' and '--columns all'.",
+				}}
+			}
+			if !ok {
+				findings = append(findings, Finding{
+					Severity: severity,
+					Entity:   definition.Entity,
+					Name:     rowName(definition.Entity, row),
+					Message:  message,
+					Hint:     definition.Hint,
+				})
+			}
+		}
+		return findings
+	}
+
+	return Rule{Name: definition.Name, Description: definition.Assert, Check: check}, nil
+}
+
+// checkColumns returns an error when the expression refers to a column the rows of the
+// entity do not have.
+func checkColumns(definition RuleDefinition, expression *Expression) error {
+	if expression == nil {
+		return nil
+	}
+
+	columnDefs, _ := entities.Columns(definition.Entity)
+	known := make(map[string]bool, len(columnDefs))
+	for _, columnDef := range columnDefs {
+		known[columnDef.Header] = true
+	}
+
+	for _, column := range expression.Columns() {
+		if !known[column] {
+			return fmt.Errorf("rule %s: unknown column %s for entity %s", definition.Name, column, definition.Entity)
+		}
+	}
+	return nil
+}
+
+// evaluateRule reports whether the row passes the rule. Rows not matching the filter pass.
+func evaluateRule(filter, assert *Expression, row map[string]string) (bool, error) {
+	if filter != nil {
+		matches, err := filter.Evaluate(row)
+		if err != nil || !matches {
+			return true, err
+		}
+	}
+	return assert.Evaluate(row)
+}
+
+func rowName(entity string, row map[string]string) string {
+	name := ""
+	for i, column := range nameColumns[entity] {
+		if i > 0 {
+			name += "/"
+		}
+		name += row[column]
+	}
+	return name
+}
@@ -0,0 +1,144 @@
+package doctor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/entities"
+	"github.com/fehmicansaglam/esctl/es"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+// Finding is a problem found by a rule on a single entity of the cluster.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Entity   string   `json:"entity"`
+	Name     string   `json:"name"`
+	Message  string   `json:"message"`
+	Hint     string   `json:"hint"`
+}
+
+// Rule checks the snapshot for one kind of problem.
+type Rule struct {
+	Name        string
+	Description string
+	Check       func(snapshot *Snapshot) []Finding
+}
+
+// Snapshot is the state of the cluster the rules are evaluated against.
+type Snapshot struct {
+	Health          es.ClusterHealth
+	Nodes           []es.Node
+	Indices         []es.Index
+	Shards          []es.Shard
+	Aliases         map[string]string
+	ClusterSettings es.FlatClusterSettings
+	IndexSettings   map[string]map[string]interface{}
+
+	// Rows holds the rows of every entity as shown by get, for the custom rules.
+	Rows map[string][]map[string]string
+}
+
+// Collect fetches everything the built-in rules need from the cluster.
+func Collect() (*Snapshot, error) {
+	var snapshot Snapshot
+	var err error
+
+	if snapshot.Health, err = es.GetClusterHealth(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve cluster health: %w", err)
+	}
+	if snapshot.Nodes, err = es.GetNodes(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve nodes: %w", err)
+	}
+	if snapshot.Indices, err = es.GetIndices(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve indices: %w", err)
+	}
+	if snapshot.Shards, err = es.GetShards(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve shards: %w", err)
+	}
+	if snapshot.Aliases, err = es.GetAliases(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve aliases: %w", err)
+	}
+	if snapshot.ClusterSettings, err = es.GetFlatClusterSettings(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve cluster settings: %w", err)
+	}
+	if snapshot.IndexSettings, err = es.GetFlatIndexSettings(""); err != nil {
+		return nil, fmt.Errorf("failed to retrieve index settings: %w", err)
+	}
+	snapshot.Rows = entityRows(&snapshot)
+
+	return &snapshot, nil
+}
+
+// entityRows converts the snapshot into the rows get prints for every entity.
+func entityRows(snapshot *Snapshot) map[string][]map[string]string {
+	rows := map[string][]map[string]string{
+		constants.EntityAlias: entities.AliasRows(snapshot.Aliases),
+	}
+	for _, index := range snapshot.Indices {
+		rows[constants.EntityIndex] = append(rows[constants.EntityIndex], entities.IndexRow(index))
+	}
+	for _, node := range snapshot.Nodes {
+		rows[constants.EntityNode] = append(rows[constants.EntityNode], entities.NodeRow(node))
+	}
+	for _, shard := range snapshot.Shards {
+		rows[constants.EntityShard] = append(rows[constants.EntityShard], entities.ShardRow(shard))
+	}
+	return rows
+}
+
+// Run evaluates the rules against the snapshot and returns the findings, the most severe
+// first.
+func Run(snapshot *Snapshot, rules []Rule) []Finding {
+	findings := make([]Finding, 0)
+	for _, rule := range rules {
+		for _, finding := range rule.Check(snapshot) {
+			finding.Rule = rule.Name
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		left, right := findings[i], findings[j]
+		if left.Severity != right.Severity {
+			return left.Severity.rank() > right.Severity.rank()
+		}
+		if left.Rule != right.Rule {
+			return left.Rule < right.Rule
+		}
+		if left.Entity != right.Entity {
+			return left.Entity < right.Entity
+		}
+		return left.Name < right.Name
+	})
+
+	return findings
+}
+
+func HasCritical(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityCritical {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,459 @@
+package doctor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fehmicansaglam/esctl/output"
+)
+
+// Expression is a boolean expression over the columns of a row, e.g.
+//
+//	INDEX =~ "^logs-" && REPLICAS >= 1 && STORE-SIZE < 50gb
+//
+// Columns are compared as numbers when both sides are numbers or percentages, as data
+// sizes when both sides are sizes and as strings otherwise.
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+type exprNode interface {
+	eval(row map[string]string) (bool, error)
+	columns() []string
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+var comparisonOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "=~": true, "!~": true,
+}
+
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+func (e *Expression) Evaluate(row map[string]string) (bool, error) {
+	return e.root.eval(row)
+}
+
+// Columns returns the columns the expression refers to, in the order they first appear.
+func (e *Expression) Columns() []string {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, column := range e.root.columns() {
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+func isIdentPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, value: ")", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var value strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value.String(), pos: start})
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// A unit may follow the number, e.g. 50gb or 85%.
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || runes[i] == '%') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:i]), pos: start})
+		case isIdentStart(r):
+			start := i
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, token{kind: tokenOperator, value: "&&", pos: start})
+			case "or":
+				tokens = append(tokens, token{kind: tokenOperator, value: "||", pos: start})
+			case "not":
+				tokens = append(tokens, token{kind: tokenOperator, value: "!", pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokenIdent, value: strings.ToUpper(word), pos: start})
+			}
+		default:
+			start := i
+			operator := string(r)
+			if i+1 < len(runes) {
+				if two := string(runes[i : i+2]); comparisonOperators[two] || two == "&&" || two == "||" {
+					operator = two
+				}
+			}
+			if !comparisonOperators[operator] && operator != "&&" && operator != "||" && operator != "!" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, start)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: operator, pos: start})
+			i += len([]rune(operator))
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, value: "end of expression", pos: len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOperator && p.peek().value == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOperator && p.peek().value == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (exprNode, error) {
+	if t := p.peek(); t.kind == tokenOperator && t.value == "!" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+
+	if p.peek().kind == tokenLeftParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRightParen {
+			return nil, fmt.Errorf("expected ) at position %d, got %q", t.pos, t.value)
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind != tokenOperator || !comparisonOperators[t.value] {
+		return truthyNode{left}, nil
+	}
+	p.next()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	node := comparisonNode{left: left, operator: t.value, right: right}
+	if t.value == "=~" || t.value == "!~" {
+		if right.column != "" {
+			return nil, fmt.Errorf("expected a regular expression after %s at position %d", t.value, t.pos)
+		}
+		if node.regexp, err = regexp.Compile(right.literal); err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", right.literal, err)
+		}
+	}
+
+	return node, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenIdent:
+		return operand{column: t.value}, nil
+	case tokenString, tokenNumber:
+		return operand{literal: t.value}, nil
+	}
+	return operand{}, fmt.Errorf("expected a column or a value at position %d, got %q", t.pos, t.value)
+}
+
+// operand is either a column of the row or a literal value.
+type operand struct {
+	column  string
+	literal string
+}
+
+func (o operand) value(row map[string]string) (string, error) {
+	if o.column == "" {
+		return o.literal, nil
+	}
+
+	value, ok := row[o.column]
+	if !ok {
+		return "", fmt.Errorf("unknown column %s", o.column)
+	}
+	return value, nil
+}
+
+func (o operand) columns() []string {
+	if o.column == "" {
+		return nil
+	}
+	return []string{o.column}
+}
+
+type orNode struct{ left, right exprNode }
+
+func (n orNode) eval(row map[string]string) (bool, error) {
+	left, err := n.left.eval(row)
+	if err != nil || left {
+		return left, err
+	}
+	return n.right.eval(row)
+}
+
+func (n orNode) columns() []string {
+	return append(n.left.columns(), n.right.columns()...)
+}
+
+type andNode struct{ left, right exprNode }
+
+func (n andNode) eval(row map[string]string) (bool, error) {
+	left, err := n.left.eval(row)
+	if err != nil || !left {
+		return false, err
+	}
+	return n.right.eval(row)
+}
+
+func (n andNode) columns() []string {
+	return append(n.left.columns(), n.right.columns()...)
+}
+
+type notNode struct{ operand exprNode }
+
+func (n notNode) eval(row map[string]string) (bool, error) {
+	value, err := n.operand.eval(row)
+	return !value, err
+}
+
+func (n notNode) columns() []string {
+	return n.operand.columns()
+}
+
+// truthyNode is an operand used on its own, which is true unless it is empty, false or 0.
+type truthyNode struct{ operand operand }
+
+func (n truthyNode) eval(row map[string]string) (bool, error) {
+	value, err := n.operand.value(row)
+	if err != nil {
+		return false, err
+	}
+	return value != "" && value != "false" && value != "0", nil
+}
+
+func (n truthyNode) columns() []string {
+	return n.operand.columns()
+}
+
+type comparisonNode struct {
+	left     operand
+	operator string
+	right    operand
+	regexp   *regexp.Regexp
+}
+
+func (n comparisonNode) eval(row map[string]string) (bool, error) {
+	left, err := n.left.value(row)
+	if err != nil {
+		return false, err
+	}
+
+	switch n.operator {
+	case "=~":
+		return n.regexp.MatchString(left), nil
+	case "!~":
+		return !n.regexp.MatchString(left), nil
+	}
+
+	right, err := n.right.value(row)
+	if err != nil {
+		return false, err
+	}
+
+	c := compareValues(left, right)
+	switch n.operator {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func (n comparisonNode) columns() []string {
+	return append(n.left.columns(), n.right.columns()...)
+}
+
+// compareValues compares two values as numbers, data sizes or strings, in this order of
+// preference, and returns -1, 0 or 1.
+func compareValues(left, right string) int {
+	if a, b, ok := parseBoth(left, right, parseNumeric); ok {
+		return compareFloats(a, b)
+	}
+	if a, b, ok := parseBoth(left, right, parseSize); ok {
+		return compareFloats(a, b)
+	}
+	return strings.Compare(left, right)
+}
+
+func parseBoth(left, right string, parse func(string) (float64, error)) (float64, float64, bool) {
+	a, err := parse(left)
+	if err != nil {
+		return 0, 0, false
+	}
+	b, err := parse(right)
+	if err != nil {
+		return 0, 0, false
+	}
+	return a, b, true
+}
+
+func parseNumeric(value string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+}
+
+func parseSize(value string) (float64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	return output.ParseDataSize(value)
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package doctor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fehmicansaglam/esctl/constants"
+)
+
+func TestExpression(t *testing.T) {
+	row := map[string]string{
+		"INDEX":      "logs-2023.05.07",
+		"HEALTH":     "yellow",
+		"REPLICAS":   "0",
+		"DOCS-COUNT": "1500",
+		"STORE-SIZE": "72.5gb",
+		"CPU":        "35%",
+		"ALIAS":      "",
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{`REPLICAS >= 1`, false},
+		{`REPLICAS == 0`, true},
+		{`DOCS-COUNT > 1000`, true},
+		{`DOCS-COUNT>1000 && DOCS-COUNT<=1500`, true},
+		{`STORE-SIZE < 50gb`, false},
+		{`STORE-SIZE > 1024mb`, true},
+		{`CPU < 50%`, true},
+		{`INDEX =~ "^logs-"`, true},
+		{`INDEX !~ '^logs-'`, false},
+		{`HEALTH == "green" || HEALTH == "yellow"`, true},
+		{`health != "green" and not (replicas > 0)`, true},
+		{`!(HEALTH == "yellow")`, false},
+		{`INDEX`, true},
+		{`ALIAS`, false},
+		{`HEALTH == "red" || REPLICAS == 0 && DOCS-COUNT > 2000`, false},
+		{`"b" > "a"`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			expression, err := ParseExpression(test.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := expression.Evaluate(row)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`REPLICAS >=`,
+		`(REPLICAS > 1`,
+		`REPLICAS > 1)`,
+		`INDEX = "a"`,
+		`INDEX == "a`,
+		`INDEX =~ OTHER`,
+		`INDEX =~ "("`,
+		`REPLICAS > 1 REPLICAS`,
+	}
+
+	for _, test := range tests {
+		if _, err := ParseExpression(test); err == nil {
+			t.Errorf("expected an error for %q", test)
+		}
+	}
+
+	expression, err := ParseExpression(`UNKNOWN == 1`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := expression.Evaluate(map[string]string{}); err == nil {
+		t.Errorf("expected an error for an unknown column")
+	}
+}
+
+func TestExpressionColumns(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   []string
+	}{
+		{`REPLICAS >= 1`, []string{"REPLICAS"}},
+		{`1 <= REPLICAS`, []string{"REPLICAS"}},
+		{`INDEX =~ "^logs-" && !(REPLICAS > 0 || REPLICAS == SHARDS)`, []string{"INDEX", "REPLICAS", "SHARDS"}},
+		{`ALIAS`, []string{"ALIAS"}},
+		{`"a" == "a"`, nil},
+	}
+
+	for _, test := range tests {
+		expression, err := ParseExpression(test.expression)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", test.expression, err)
+		}
+		if columns := expression.Columns(); !reflect.DeepEqual(columns, test.expected) {
+			t.Errorf("%s: expected columns %v, got %v", test.expression, test.expected, columns)
+		}
+	}
+}
+
+func TestCustomRule(t *testing.T) {
+	snapshot := &Snapshot{Rows: map[string][]map[string]string{
+		constants.EntityIndex: {
+			{"INDEX": "logs-1", "REPLICAS": "0"},
+			{"INDEX": "logs-2", "REPLICAS": "1"},
+			{"INDEX": "metrics-1", "REPLICAS": "0"},
+		},
+		constants.EntityShard: {
+			{"INDEX": "logs-1", "SHARD": "0", "PRI-REP": "primary", "STATE": "UNASSIGNED"},
+		},
+	}}
+
+	tests := []struct {
+		name       string
+		definition RuleDefinition
+		expected   []string
+	}{
+		{
+			name: "filter and assert",
+			definition: RuleDefinition{
+				Name: "logs-replicas", Entity: "index", Filter: `INDEX =~ "^logs-"`, Assert: "REPLICAS >= 1", Severity: "critical",
+			},
+			expected: []string{"critical index logs-1"},
+		},
+		{
+			name:       "assert only",
+			definition: RuleDefinition{Name: "replicas", Entity: "index", Assert: "REPLICAS >= 1"},
+			expected:   []string{"warning index logs-1", "warning index metrics-1"},
+		},
+		{
+			name:       "shard name",
+			definition: RuleDefinition{Name: "started", Entity: "shard", Assert: `STATE == "STARTED"`},
+			expected:   []string{"warning shard logs-1/0/primary"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, err := NewCustomRule(test.definition)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			names := findingNames(rule.Check(snapshot))
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected findings %v, got %v", test.expected, names)
+			}
+		})
+	}
+
+	invalid := []RuleDefinition{
+		{Entity: "index", Assert: "REPLICAS >= 1"},
+		{Name: "a", Entity: "task", Assert: "REPLICAS >= 1"},
+		{Name: "a", Entity: "index"},
+		{Name: "a", Entity: "index", Assert: "REPLICAS >= 1", Severity: "fatal"},
+		{Name: "a", Entity: "index", Assert: "REPLICAS >="},
+		{Name: "a", Entity: "index", Filter: "(", Assert: "REPLICAS >= 1"},
+		{Name: "a", Entity: "index", Assert: "SIZE > 1gb"},
+		{Name: "a", Entity: "index", Filter: `NODE == "n1"`, Assert: "REPLICAS >= 1"},
+		{Name: "a", Entity: "shard", Assert: "REPLICAS >= 1 || STATE == STARTED"},
+	}
+	for _, definition := range invalid {
+		if _, err := NewCustomRule(definition); err == nil {
+			t.Errorf("expected an error for %+v", definition)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package doctor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+)
+
+const (
+	gb = 1024 * 1024 * 1024
+
+	maxShardSize            = 50 * gb
+	minAverageShardSize     = 1 * gb
+	heapWarningPercent      = 85
+	heapCriticalPercent     = 95
+	defaultMaxShardsPerNode = 1000
+	shardsPerNodeWarning    = 0.8
+	imbalanceRatio          = 0.2
+	imbalanceMinShards      = 10
+)
+
+var defaultWatermarks = map[string]string{
+	"low":         "85%",
+	"high":        "90%",
+	"flood_stage": "95%",
+}
+
+var deprecatedSettings = []struct {
+	prefix string
+	hint   string
+}{
+	{"discovery.zen.", "Zen discovery was removed in 7.0; use discovery.seed_hosts and cluster.initial_master_nodes instead."},
+	{"search.remote.", "Remote cluster settings were renamed to cluster.remote.* in 6.5."},
+	{"cluster.routing.allocation.disk.watermark.enable_for_single_data_node", "The setting is deprecated since 7.14 and has no effect in 8.0; remove it."},
+	{"xpack.monitoring.collection.enabled", "Legacy monitoring collection is deprecated since 7.16; collect with Metricbeat or Elastic Agent instead."},
+	{"index.translog.retention.", "Translog retention is deprecated since 7.4 as peer recoveries use soft deletes; remove the setting."},
+	{"index.mapper.dynamic", "The setting was removed in 7.0; use the dynamic mapping parameter instead."},
+}
+
+// BuiltinRules are the rules run by doctor unless other rules are given.
+var BuiltinRules = []Rule{
+	{Name: "index-health", Description: "Indices that are yellow or red", Check: checkIndexHealth},
+	{Name: "oversized-shards", Description: "Primary shards larger than 50gb", Check: checkOversizedShards},
+	{Name: "small-shards", Description: "Indices with many primary shards averaging less than 1gb", Check: checkSmallShards},
+	{Name: "zero-replicas", Description: "Indices without replicas", Check: checkZeroReplicas},
+	{Name: "shards-per-node", Description: "Nodes close to cluster.max_shards_per_node", Check: checkShardsPerNode},
+	{Name: "unbalanced-shards", Description: "Data nodes with far more or fewer shards than the average", Check: checkUnbalancedShards},
+	{Name: "disk-watermarks", Description: "Nodes over the disk watermarks", Check: checkDiskWatermarks},
+	{Name: "high-heap", Description: "Nodes with high heap usage", Check: checkHighHeap},
+	{Name: "deprecated-settings", Description: "Cluster and index settings that are deprecated or removed", Check: checkDeprecatedSettings},
+}
+
+func checkIndexHealth(snapshot *Snapshot) []Finding {
+	var findings []Finding
+	for _, index := range snapshot.Indices {
+		switch index.Health {
+		case "red":
+			findings = append(findings, Finding{
+				Severity: SeverityCritical,
+				Entity:   constants.EntityIndex,
+				Name:     index.Index,
+				Message:  "Index is red, some primary shards are unassigned",
+				Hint:     "Check the unassigned shards with 'esctl get shards --unassigned' and the allocation explain API.",
+			})
+		case "yellow":
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Entity:   constants.EntityIndex,
+				Name:     index.Index,
+				Message:  "Index is yellow, some replica shards are unassigned",
+				Hint:     "Make sure there are enough data nodes for the replicas or lower number_of_replicas.",
+			})
+		}
+	}
+	return findings
+}
+
+func checkOversizedShards(snapshot *Snapshot) []Finding {
+	var findings []Finding
+	for _, shard := range snapshot.Shards {
+		if shard.PriRep != constants.ShardPrimary {
+			continue
+		}
+
+		size, _ := output.ParseDataSize(shard.Store)
+		if size > maxShardSize {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Entity:   constants.EntityShard,
+				Name:     fmt.Sprintf("%s/%s", shard.Index, shard.Shard),
+				Message:  fmt.Sprintf("Primary shard is %s, larger than 50gb", shard.Store),
+				Hint:     "Split the index or roll over to a new index earlier to keep shards between 10gb and 50gb.",
+			})
+		}
+	}
+	return findings
+}
+
+func checkSmallShards(snapshot *Snapshot) []Finding {
+	var findings []Finding
+	for _, index := range snapshot.Indices {
+		primaries := parseNumber(index.Pri)
+		if primaries <= 1 {
+			continue
+		}
+
+		size, err := output.ParseDataSize(index.PriStoreSize)
+		if err != nil {
+			continue
+		}
+
+		if average := size / primaries; average < minAverageShardSize {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Entity:   constants.EntityIndex,
+				Name:     index.Index,
+				Message:  fmt.Sprintf("%s primary shards hold %s in total", index.Pri, index.PriStoreSize),
+				Hint:     "Shrink the index or create it with fewer primary shards; every shard has a fixed overhead.",
+			})
+		}
+	}
+	return findings
+}
+
+func checkZeroReplicas(snapshot *Snapshot) []Finding {
+	var findings []Finding
+	for _, index := range snapshot.Indices {
+		if index.Rep != "0" || strings.HasPrefix(index.Index, ".") {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Entity:   constants.EntityIndex,
+			Name:     index.Index,
+			Message:  "Index has no replicas, losing a node loses data",
+			Hint:     "Set index.number_of_replicas to at least 1 unless the index can be rebuilt.",
+		})
+	}
+	return findings
+}
+
+func checkShardsPerNode(snapshot *Snapshot) []Finding {
+	limit := float64(defaultMaxShardsPerNode)
+	if value, ok := snapshot.ClusterSettings.Get("cluster.max_shards_per_node"); ok {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	counts := shardCounts(snapshot)
+
+	var findings []Finding
+	for _, node := range snapshot.Nodes {
+		count := float64(counts[node.Name])
+
+		var severity Severity
+		switch {
+		case count > limit:
+			severity = SeverityCritical
+		case count > limit*shardsPerNodeWarning:
+			severity = SeverityWarning
+		default:
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity: severity,
+			Entity:   constants.EntityNode,
+			Name:     node.Name,
+			Message:  fmt.Sprintf("Node holds %d shards, the limit is %.0f", counts[node.Name], limit),
+			Hint:     "Delete or shrink small indices, or add data nodes; raising cluster.max_shards_per_node only hides the problem.",
+		})
+	}
+	return findings
+}
+
+func checkUnbalancedShards(snapshot *Snapshot) []Finding {
+	counts := shardCounts(snapshot)
+
+	var dataNodes []es.Node
+	total := 0
+	for _, node := range snapshot.Nodes {
+		if isDataNode(node) {
+			dataNodes = append(dataNodes, node)
+			total += counts[node.Name]
+		}
+	}
+	if len(dataNodes) < 2 {
+		return nil
+	}
+
+	average := float64(total) / float64(len(dataNodes))
+
+	var findings []Finding
+	for _, node := range dataNodes {
+		count := float64(counts[node.Name])
+		difference := count - average
+		if difference < 0 {
+			difference = -difference
+		}
+
+		if difference >= imbalanceMinShards && difference > average*imbalanceRatio {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Entity:   constants.EntityNode,
+				Name:     node.Name,
+				Message:  fmt.Sprintf("Node holds %d shards, the average of the data nodes is %.1f", counts[node.Name], average),
+				Hint:     "Check allocation filtering, awareness and total_shards_per_node settings that may keep shards off some nodes.",
+			})
+		}
+	}
+	return findings
+}
+
+func checkDiskWatermarks(snapshot *Snapshot) []Finding {
+	levels := []struct {
+		name     string
+		severity Severity
+		hint     string
+	}{
+		{"flood_stage", SeverityCritical, "Indices with shards on the node are made read-only; free disk space or add nodes immediately."},
+		{"high", SeverityCritical, "Shards are being relocated away from the node; free disk space or add nodes."},
+		{"low", SeverityWarning, "No new shards are allocated to the node; free disk space or add nodes."},
+	}
+
+	var findings []Finding
+	for _, node := range snapshot.Nodes {
+		if node.DiskTotal == "" {
+			continue
+		}
+
+		for _, level := range levels {
+			watermark, ok := snapshot.ClusterSettings.Get("cluster.routing.allocation.disk.watermark." + level.name)
+			if !ok {
+				watermark = defaultWatermarks[level.name]
+			}
+
+			if exceedsWatermark(node, watermark) {
+				findings = append(findings, Finding{
+					Severity: level.severity,
+					Entity:   constants.EntityNode,
+					Name:     node.Name,
+					Message:  fmt.Sprintf("Disk usage of %s / %s is over the %s watermark (%s)", node.DiskUsed, node.DiskTotal, level.name, watermark),
+					Hint:     level.hint,
+				})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+func checkHighHeap(snapshot *Snapshot) []Finding {
+	var findings []Finding
+	for _, node := range snapshot.Nodes {
+		heap := parseNumber(node.HeapPercent)
+
+		var severity Severity
+		switch {
+		case heap >= heapCriticalPercent:
+			severity = SeverityCritical
+		case heap >= heapWarningPercent:
+			severity = SeverityWarning
+		default:
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity: severity,
+			Entity:   constants.EntityNode,
+			Name:     node.Name,
+			Message:  fmt.Sprintf("Heap usage is %s%%", node.HeapPercent),
+			Hint:     "Look for large aggregations, fielddata and too many shards; consider more heap or more nodes.",
+		})
+	}
+	return findings
+}
+
+func checkDeprecatedSettings(snapshot *Snapshot) []Finding {
+	var findings []Finding
+
+	check := func(entity, name string, settings map[string]interface{}) {
+		keys := make([]string, 0, len(settings))
+		for key := range settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			for _, deprecated := range deprecatedSettings {
+				if strings.HasPrefix(key, deprecated.prefix) {
+					findings = append(findings, Finding{
+						Severity: SeverityWarning,
+						Entity:   entity,
+						Name:     name,
+						Message:  fmt.Sprintf("Setting %s is deprecated", key),
+						Hint:     deprecated.hint,
+					})
+					break
+				}
+			}
+		}
+	}
+
+	check(constants.EntityCluster, "persistent", snapshot.ClusterSettings.Persistent)
+	check(constants.EntityCluster, "transient", snapshot.ClusterSettings.Transient)
+	for index, settings := range snapshot.IndexSettings {
+		check(constants.EntityIndex, index, settings)
+	}
+
+	return findings
+}
+
+// shardCounts returns the number of shards assigned to every node by node name.
+func shardCounts(snapshot *Snapshot) map[string]int {
+	counts := make(map[string]int)
+	for _, shard := range snapshot.Shards {
+		// Relocating shards are listed as "source -> ip id target".
+		if fields := strings.Fields(shard.Node); len(fields) > 0 {
+			counts[fields[0]]++
+		}
+	}
+	return counts
+}
+
+func isDataNode(node es.Node) bool {
+	return strings.ContainsAny(node.NodeRole, "dhwcfs")
+}
+
+// exceedsWatermark reports whether the disk usage of the node is over the watermark, given
+// either as a percentage or ratio of used disk or as an amount of free disk.
+func exceedsWatermark(node es.Node, watermark string) bool {
+	used, _ := output.ParseDataSize(node.DiskUsed)
+	total, err := output.ParseDataSize(node.DiskTotal)
+	if err != nil || total == 0 {
+		return false
+	}
+
+	if strings.HasSuffix(watermark, "%") {
+		return used/total*100 >= parseNumber(watermark)
+	}
+
+	if ratio, err := strconv.ParseFloat(watermark, 64); err == nil {
+		return used/total >= ratio
+	}
+
+	free, err := output.ParseDataSize(watermark)
+	if err != nil {
+		return false
+	}
+	available, _ := output.ParseDataSize(node.DiskAvail)
+	return available <= free
+}
+
+func parseNumber(value string) float64 {
+	number, _ := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	return number
+}
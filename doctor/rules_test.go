@@ -0,0 +1,184 @@
+package doctor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fehmicansaglam/esctl/es"
+)
+
+func findingNames(findings []Finding) []string {
+	names := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		names = append(names, string(finding.Severity)+" "+finding.Entity+" "+finding.Name)
+	}
+	return names
+}
+
+func shardsOn(node string, count int) []es.Shard {
+	shards := make([]es.Shard, count)
+	for i := range shards {
+		shards[i] = es.Shard{Index: "logs", Shard: "0", PriRep: "r", Node: node}
+	}
+	return shards
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    func(*Snapshot) []Finding
+		snapshot Snapshot
+		expected []string
+	}{
+		{
+			name:  "index health",
+			check: checkIndexHealth,
+			snapshot: Snapshot{Indices: []es.Index{
+				{Index: "a", Health: "green"},
+				{Index: "b", Health: "yellow"},
+				{Index: "c", Health: "red"},
+			}},
+			expected: []string{"warning index b", "critical index c"},
+		},
+		{
+			name:  "oversized shards",
+			check: checkOversizedShards,
+			snapshot: Snapshot{Shards: []es.Shard{
+				{Index: "a", Shard: "0", PriRep: "p", Store: "72gb"},
+				{Index: "a", Shard: "0", PriRep: "r", Store: "72gb"},
+				{Index: "a", Shard: "1", PriRep: "p", Store: "40gb"},
+			}},
+			expected: []string{"warning shard a/0"},
+		},
+		{
+			name:  "small shards",
+			check: checkSmallShards,
+			snapshot: Snapshot{Indices: []es.Index{
+				{Index: "a", Pri: "5", PriStoreSize: "100mb"},
+				{Index: "b", Pri: "1", PriStoreSize: "1kb"},
+				{Index: "c", Pri: "2", PriStoreSize: "30gb"},
+			}},
+			expected: []string{"warning index a"},
+		},
+		{
+			name:  "zero replicas",
+			check: checkZeroReplicas,
+			snapshot: Snapshot{Indices: []es.Index{
+				{Index: "a", Rep: "0"},
+				{Index: "b", Rep: "1"},
+				{Index: ".security", Rep: "0"},
+			}},
+			expected: []string{"warning index a"},
+		},
+		{
+			name:  "shards per node",
+			check: checkShardsPerNode,
+			snapshot: Snapshot{
+				Nodes: []es.Node{{Name: "n1"}, {Name: "n2"}, {Name: "n3"}},
+				Shards: append(append(shardsOn("n1", 9), shardsOn("n2", 11)...),
+					es.Shard{Node: "n3 -> 10.0.0.1 id n1"}),
+				ClusterSettings: es.FlatClusterSettings{Persistent: map[string]interface{}{"cluster.max_shards_per_node": "10"}},
+			},
+			expected: []string{"warning node n1", "critical node n2"},
+		},
+		{
+			name:  "unbalanced shards",
+			check: checkUnbalancedShards,
+			snapshot: Snapshot{
+				Nodes: []es.Node{
+					{Name: "n1", NodeRole: "dim"},
+					{Name: "n2", NodeRole: "dim"},
+					{Name: "n3", NodeRole: "hs"},
+					{Name: "master", NodeRole: "m"},
+				},
+				Shards: append(append(shardsOn("n1", 40), shardsOn("n2", 40)...), shardsOn("n3", 20)...),
+			},
+			expected: []string{"warning node n3"},
+		},
+		{
+			name:  "disk watermarks",
+			check: checkDiskWatermarks,
+			snapshot: Snapshot{
+				Nodes: []es.Node{
+					{Name: "n1", DiskUsed: "50gb", DiskTotal: "100gb", DiskAvail: "50gb"},
+					{Name: "n2", DiskUsed: "87gb", DiskTotal: "100gb", DiskAvail: "13gb"},
+					{Name: "n3", DiskUsed: "96gb", DiskTotal: "100gb", DiskAvail: "4gb"},
+				},
+				ClusterSettings: es.FlatClusterSettings{Defaults: map[string]interface{}{
+					"cluster.routing.allocation.disk.watermark.high": "0.9",
+				}},
+			},
+			expected: []string{"warning node n2", "critical node n3"},
+		},
+		{
+			name:  "absolute disk watermark",
+			check: checkDiskWatermarks,
+			snapshot: Snapshot{
+				Nodes: []es.Node{{Name: "n1", DiskUsed: "50gb", DiskTotal: "100gb", DiskAvail: "50gb"}},
+				ClusterSettings: es.FlatClusterSettings{Persistent: map[string]interface{}{
+					"cluster.routing.allocation.disk.watermark.low": "60gb",
+				}},
+			},
+			expected: []string{"warning node n1"},
+		},
+		{
+			name:  "high heap",
+			check: checkHighHeap,
+			snapshot: Snapshot{Nodes: []es.Node{
+				{Name: "n1", HeapPercent: "50"},
+				{Name: "n2", HeapPercent: "88"},
+				{Name: "n3", HeapPercent: "97"},
+			}},
+			expected: []string{"warning node n2", "critical node n3"},
+		},
+		{
+			name:  "deprecated settings",
+			check: checkDeprecatedSettings,
+			snapshot: Snapshot{
+				ClusterSettings: es.FlatClusterSettings{Persistent: map[string]interface{}{
+					"discovery.zen.minimum_master_nodes": "2",
+					"cluster.routing.allocation.enable":  "all",
+				}},
+				IndexSettings: map[string]map[string]interface{}{
+					"a": {"index.translog.retention.size": "1gb", "index.number_of_shards": "1"},
+				},
+			},
+			expected: []string{"warning cluster persistent", "warning index a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			names := findingNames(test.check(&test.snapshot))
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected findings %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	snapshot := &Snapshot{
+		Indices: []es.Index{
+			{Index: "b", Health: "yellow", Rep: "0"},
+			{Index: "a", Health: "red", Rep: "1"},
+		},
+	}
+	rules := []Rule{
+		{Name: "zero-replicas", Check: checkZeroReplicas},
+		{Name: "index-health", Check: checkIndexHealth},
+	}
+
+	findings := Run(snapshot, rules)
+
+	expected := []string{"critical index a", "warning index b", "warning index b"}
+	if names := findingNames(findings); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected findings %v, got %v", expected, names)
+	}
+	if findings[1].Rule != "index-health" || findings[2].Rule != "zero-replicas" {
+		t.Errorf("expected findings of the same severity to be sorted by rule, got %s and %s", findings[1].Rule, findings[2].Rule)
+	}
+	if !HasCritical(findings) {
+		t.Errorf("expected a critical finding")
+	}
+}
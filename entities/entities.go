@@ -0,0 +1,157 @@
+// Package entities converts the entities of the cluster into the rows 'esctl get' prints
+// and custom doctor rules are evaluated against, keyed by column header.
+package entities
+
+import (
+	"github.com/fehmicansaglam/esctl/constants"
+	"github.com/fehmicansaglam/esctl/es"
+	"github.com/fehmicansaglam/esctl/output"
+)
+
+var AliasColumns = []output.ColumnDef{
+	{Header: "ALIAS", Type: output.Text},
+	{Header: "INDEX", Type: output.Text},
+}
+
+var IndexColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "UUID", Type: output.Text},
+	{Header: "HEALTH", Type: output.Text},
+	{Header: "STATUS", Type: output.Text},
+	{Header: "SHARDS", Type: output.Number},
+	{Header: "REPLICAS", Type: output.Number},
+	{Header: "DOCS-COUNT", Type: output.Number},
+	{Header: "DOCS-DELETED", Type: output.Number},
+	{Header: "CREATION-DATE", Type: output.Date},
+	{Header: "STORE-SIZE", Type: output.DataSize},
+	{Header: "PRI-STORE-SIZE", Type: output.DataSize},
+}
+
+var NodeColumns = []output.ColumnDef{
+	{Header: "NAME", Type: output.Text},
+	{Header: "IP", Type: output.Text},
+	{Header: "NODE-ROLE", Type: output.Text},
+	{Header: "MASTER", Type: output.Text},
+	{Header: "HEAP-MAX", Type: output.DataSize},
+	{Header: "HEAP-CURRENT", Type: output.DataSize},
+	{Header: "HEAP-PERCENT", Type: output.Percent},
+	{Header: "RAM-MAX", Type: output.DataSize},
+	{Header: "RAM-CURRENT", Type: output.DataSize},
+	{Header: "RAM-PERCENT", Type: output.Percent},
+	{Header: "CPU", Type: output.Percent},
+	{Header: "LOAD-1M", Type: output.Number},
+	{Header: "DISK-TOTAL", Type: output.DataSize},
+	{Header: "DISK-USED", Type: output.DataSize},
+	{Header: "DISK-AVAILABLE", Type: output.DataSize},
+	{Header: "UPTIME", Type: output.Text},
+}
+
+var ShardColumns = []output.ColumnDef{
+	{Header: "INDEX", Type: output.Text},
+	{Header: "SHARD", Type: output.Number},
+	{Header: "PRI-REP", Type: output.Text},
+	{Header: "STATE", Type: output.Text},
+	{Header: "DOCS", Type: output.Number},
+	{Header: "STORE", Type: output.DataSize},
+	{Header: "IP", Type: output.Text},
+	{Header: "NODE", Type: output.Text},
+	{Header: "NODE-ID", Type: output.Text},
+	{Header: "UNASSIGNED-REASON", Type: output.Text},
+	{Header: "UNASSIGNED-AT", Type: output.Date},
+	{Header: "SEGMENTS-COUNT", Type: output.Number},
+}
+
+// Columns returns the columns of the rows of an entity.
+func Columns(entity string) ([]output.ColumnDef, bool) {
+	switch entity {
+	case constants.EntityAlias:
+		return AliasColumns, true
+	case constants.EntityIndex:
+		return IndexColumns, true
+	case constants.EntityNode:
+		return NodeColumns, true
+	case constants.EntityShard:
+		return ShardColumns, true
+	}
+	return nil, false
+}
+
+// AliasRows returns a row for every alias, keyed by column header.
+func AliasRows(aliases map[string]string) []map[string]string {
+	rows := make([]map[string]string, 0, len(aliases))
+	for alias, index := range aliases {
+		rows = append(rows, map[string]string{
+			"ALIAS": alias,
+			"INDEX": index,
+		})
+	}
+	return rows
+}
+
+// IndexRow returns the columns of the index keyed by column header.
+func IndexRow(index es.Index) map[string]string {
+	return map[string]string{
+		"INDEX":          index.Index,
+		"UUID":           index.UUID,
+		"HEALTH":         index.Health,
+		"STATUS":         index.Status,
+		"SHARDS":         index.Pri,
+		"REPLICAS":       index.Rep,
+		"DOCS-COUNT":     index.DocsCount,
+		"DOCS-DELETED":   index.DocsDeleted,
+		"CREATION-DATE":  index.CreationDate,
+		"STORE-SIZE":     index.StoreSize,
+		"PRI-STORE-SIZE": index.PriStoreSize,
+	}
+}
+
+// NodeRow returns the columns of the node keyed by column header.
+func NodeRow(node es.Node) map[string]string {
+	return map[string]string{
+		"NAME":           node.Name,
+		"IP":             node.IP,
+		"NODE-ROLE":      node.NodeRole,
+		"MASTER":         node.Master,
+		"HEAP-MAX":       node.HeapMax,
+		"HEAP-CURRENT":   node.HeapCurrent,
+		"HEAP-PERCENT":   node.HeapPercent + "%",
+		"RAM-MAX":        node.RAMMax,
+		"RAM-CURRENT":    node.RAMCurrent,
+		"RAM-PERCENT":    node.RAMPercent + "%",
+		"CPU":            node.CPU + "%",
+		"LOAD-1M":        node.Load1m,
+		"DISK-TOTAL":     node.DiskTotal,
+		"DISK-USED":      node.DiskUsed,
+		"DISK-AVAILABLE": node.DiskAvail,
+		"UPTIME":         node.Uptime,
+	}
+}
+
+// ShardRow returns the columns of the shard keyed by column header.
+func ShardRow(shard es.Shard) map[string]string {
+	return map[string]string{
+		"INDEX":             shard.Index,
+		"SHARD":             shard.Shard,
+		"PRI-REP":           humanizePriRep(shard.PriRep),
+		"STATE":             shard.State,
+		"DOCS":              shard.Docs,
+		"STORE":             shard.Store,
+		"IP":                shard.IP,
+		"NODE":              shard.Node,
+		"NODE-ID":           shard.ID,
+		"UNASSIGNED-REASON": shard.UnassignedReason,
+		"UNASSIGNED-AT":     shard.UnassignedAt,
+		"SEGMENTS-COUNT":    shard.SegmentsCount,
+	}
+}
+
+func humanizePriRep(priRep string) string {
+	switch priRep {
+	case constants.ShardPrimary:
+		return "primary"
+	case constants.ShardReplica:
+		return "replica"
+	default:
+		return priRep
+	}
+}
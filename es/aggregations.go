@@ -0,0 +1,446 @@
+package es
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	GroupingTerms         = "terms"
+	GroupingHistogram     = "histogram"
+	GroupingDateHistogram = "date_histogram"
+)
+
+const (
+	MetricSum         = "sum"
+	MetricAvg         = "avg"
+	MetricMin         = "min"
+	MetricMax         = "max"
+	MetricCardinality = "cardinality"
+	MetricPercentiles = "percentiles"
+)
+
+// OtherGroupKey is the key of the bucket holding the documents of the groups beyond the requested size.
+const OtherGroupKey = "(other)"
+
+// Grouping is one level of a multi-level document grouping.
+type Grouping struct {
+	Field    string
+	Type     string
+	Interval string
+}
+
+var calendarIntervals = map[string]bool{
+	"1m": true, "1h": true, "1d": true, "1w": true, "1M": true, "1q": true, "1y": true,
+	"minute": true, "hour": true, "day": true, "week": true, "month": true, "quarter": true, "year": true,
+}
+
+// ParseGrouping parses a grouping of the given type. Terms groupings take a field name,
+// histogram groupings take a field and an interval in field:interval format.
+func ParseGrouping(groupingType, value string) (Grouping, error) {
+	if groupingType == GroupingTerms {
+		if value == "" {
+			return Grouping{}, fmt.Errorf("empty group-by field")
+		}
+		return Grouping{Field: value, Type: GroupingTerms}, nil
+	}
+
+	index := strings.LastIndex(value, ":")
+	if index <= 0 || index == len(value)-1 {
+		return Grouping{}, fmt.Errorf("invalid %s format: %s (expected field:interval)", groupingType, value)
+	}
+	field, interval := value[:index], value[index+1:]
+
+	switch groupingType {
+	case GroupingHistogram:
+		if _, err := strconv.ParseFloat(interval, 64); err != nil {
+			return Grouping{}, fmt.Errorf("invalid histogram interval: %s", interval)
+		}
+	case GroupingDateHistogram:
+	default:
+		return Grouping{}, fmt.Errorf("unknown grouping type: %s", groupingType)
+	}
+
+	return Grouping{Field: field, Type: groupingType, Interval: interval}, nil
+}
+
+// Metric is a metric aggregation computed for every group.
+type Metric struct {
+	Type     string
+	Field    string
+	Percents []float64
+}
+
+// ParseMetric parses a metric in type:field format, or percentiles:field:50,95,99 for percentiles.
+func ParseMetric(value string) (Metric, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return Metric{}, fmt.Errorf("invalid metric format: %s (expected type:field)", value)
+	}
+	metric := Metric{Type: parts[0], Field: parts[1]}
+
+	switch metric.Type {
+	case MetricSum, MetricAvg, MetricMin, MetricMax, MetricCardinality:
+	case MetricPercentiles:
+		index := strings.LastIndex(metric.Field, ":")
+		if index <= 0 {
+			return Metric{}, fmt.Errorf("invalid percentiles format: %s (expected percentiles:field:50,95,99)", value)
+		}
+		for _, percent := range strings.Split(metric.Field[index+1:], ",") {
+			parsed, err := strconv.ParseFloat(percent, 64)
+			if err != nil || parsed < 0 || parsed > 100 {
+				return Metric{}, fmt.Errorf("invalid percent: %s", percent)
+			}
+			metric.Percents = append(metric.Percents, parsed)
+		}
+		metric.Field = metric.Field[:index]
+	default:
+		return Metric{}, fmt.Errorf("unknown metric type: %s", metric.Type)
+	}
+
+	return metric, nil
+}
+
+// Labels returns the names of the values of the metric, one for every percent for percentiles.
+func (m Metric) Labels() []string {
+	if m.Type != MetricPercentiles {
+		return []string{fmt.Sprintf("%s(%s)", m.Type, m.Field)}
+	}
+
+	labels := make([]string, len(m.Percents))
+	for i, percent := range m.Percents {
+		labels[i] = fmt.Sprintf("p%s(%s)", strconv.FormatFloat(percent, 'f', -1, 64), m.Field)
+	}
+	return labels
+}
+
+func (m Metric) aggregation() map[string]interface{} {
+	params := map[string]interface{}{
+		"field": m.Field,
+	}
+	if m.Type == MetricPercentiles {
+		params["percents"] = m.Percents
+		params["keyed"] = false
+	}
+	return map[string]interface{}{
+		m.Type: params,
+	}
+}
+
+func metricName(index int) string {
+	return fmt.Sprintf("metric_%d", index)
+}
+
+func buildMetricAggregations(metrics []Metric, contextPath string, nestedPaths []string) map[string]interface{} {
+	aggs := make(map[string]interface{}, len(metrics))
+	for i, metric := range metrics {
+		fieldPath, _ := getNestedPath(metric.Field, nestedPaths)
+		name, wrapped := wrapNested(metricName(i), metric.aggregation(), contextPath, fieldPath)
+		aggs[name] = wrapped
+	}
+	return aggs
+}
+
+// collectMetrics reads the metric values of a bucket. Metrics without a value, such as the
+// average of a group without the field, are left out.
+func collectMetrics(bucket map[string]interface{}, metrics []Metric) map[string]float64 {
+	values := make(map[string]float64)
+	for i, metric := range metrics {
+		agg := findAggregation(bucket, metricName(i))
+		if agg == nil {
+			continue
+		}
+
+		if metric.Type != MetricPercentiles {
+			if value, ok := agg["value"].(float64); ok {
+				values[metric.Labels()[0]] = value
+			}
+			continue
+		}
+
+		labels := metric.Labels()
+		percentiles, _ := agg["values"].([]interface{})
+		for j, rawPercentile := range percentiles {
+			percentile, ok := rawPercentile.(map[string]interface{})
+			if !ok || j >= len(labels) {
+				continue
+			}
+			if value, ok := percentile["value"].(float64); ok {
+				values[labels[j]] = value
+			}
+		}
+	}
+	return values
+}
+
+func groupName(level int) string {
+	return fmt.Sprintf("group_by_%d", level)
+}
+
+func (g Grouping) aggregation(size int, missing string) map[string]interface{} {
+	switch g.Type {
+	case GroupingHistogram:
+		interval, _ := strconv.ParseFloat(g.Interval, 64)
+		return map[string]interface{}{
+			"histogram": map[string]interface{}{
+				"field":    g.Field,
+				"interval": interval,
+			},
+		}
+	case GroupingDateHistogram:
+		intervalType := "fixed_interval"
+		if calendarIntervals[g.Interval] {
+			intervalType = "calendar_interval"
+		}
+		return map[string]interface{}{
+			"date_histogram": map[string]interface{}{
+				"field":      g.Field,
+				intervalType: g.Interval,
+			},
+		}
+	default:
+		terms := map[string]interface{}{
+			"field": g.Field,
+			"size":  size,
+		}
+		if missing != "" {
+			terms["missing"] = missing
+		}
+		return map[string]interface{}{
+			"terms": terms,
+		}
+	}
+}
+
+// compositeSource returns the source of the grouping in a composite aggregation.
+func (g Grouping) compositeSource(missing string) map[string]interface{} {
+	source := g.aggregation(0, "")
+	params := source[g.Type].(map[string]interface{})
+	delete(params, "size")
+	if g.Type == GroupingDateHistogram {
+		params["format"] = "strict_date_optional_time"
+	}
+	if missing != "" {
+		params["missing_bucket"] = true
+	}
+	return source
+}
+
+// wrapNested places an aggregation inside nested and reverse_nested aggregations so that
+// it runs in the context of toPath when its parent runs in the context of fromPath.
+// An empty path is the context of the root documents.
+func wrapNested(name string, agg map[string]interface{}, fromPath, toPath string) (string, map[string]interface{}) {
+	if fromPath == toPath {
+		return name, agg
+	}
+
+	if toPath != "" {
+		agg = map[string]interface{}{
+			"nested": map[string]interface{}{
+				"path": toPath,
+			},
+			"aggs": map[string]interface{}{
+				name: agg,
+			},
+		}
+		name = "nested_" + name
+	}
+
+	if fromPath != "" && !strings.HasPrefix(toPath, fromPath+".") {
+		agg = map[string]interface{}{
+			"reverse_nested": map[string]interface{}{},
+			"aggs": map[string]interface{}{
+				name: agg,
+			},
+		}
+		name = "reverse_nested_" + name
+	}
+
+	return name, agg
+}
+
+// buildGroupAggregations builds the aggregations of the given level and nests the
+// aggregations of the following levels into it.
+// The metrics are added to the aggregation of the last level.
+func buildGroupAggregations(groupings []Grouping, metrics []Metric, level int, contextPath string, nestedPaths []string, size int, missing string) map[string]interface{} {
+	grouping := groupings[level]
+	agg := grouping.aggregation(size, missing)
+
+	fieldPath, _ := getNestedPath(grouping.Field, nestedPaths)
+	if level+1 < len(groupings) {
+		agg["aggs"] = buildGroupAggregations(groupings, metrics, level+1, fieldPath, nestedPaths, size, missing)
+	} else if len(metrics) > 0 {
+		agg["aggs"] = buildMetricAggregations(metrics, fieldPath, nestedPaths)
+	}
+
+	name, wrapped := wrapNested(groupName(level), agg, contextPath, fieldPath)
+	return map[string]interface{}{name: wrapped}
+}
+
+// findAggregation returns the named aggregation from a response, looking through
+// the nested and reverse_nested wrappers added by wrapNested. An aggregation moving to a
+// sibling nested path is wrapped twice, e.g. reverse_nested_nested_group_by_1.
+func findAggregation(container map[string]interface{}, name string) map[string]interface{} {
+	if agg, ok := container[name].(map[string]interface{}); ok {
+		return agg
+	}
+	for key, value := range container {
+		prefix := strings.TrimSuffix(key, name)
+		if prefix == key || !isNestedWrapper(prefix) {
+			continue
+		}
+		if inner, ok := value.(map[string]interface{}); ok {
+			if agg := findAggregation(inner, name); agg != nil {
+				return agg
+			}
+		}
+	}
+	return nil
+}
+
+// isNestedWrapper reports whether the prefix is a chain of the nested_ and reverse_nested_
+// prefixes added by wrapNested.
+func isNestedWrapper(prefix string) bool {
+	for prefix != "" {
+		switch {
+		case strings.HasPrefix(prefix, "nested_"):
+			prefix = strings.TrimPrefix(prefix, "nested_")
+		case strings.HasPrefix(prefix, "reverse_nested_"):
+			prefix = strings.TrimPrefix(prefix, "reverse_nested_")
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func bucketKey(bucket map[string]interface{}) string {
+	if key, ok := bucket["key_as_string"]; ok {
+		return fmt.Sprint(key)
+	}
+	if key, ok := bucket["key"].(float64); ok {
+		return strconv.FormatFloat(key, 'f', -1, 64)
+	}
+	return fmt.Sprint(bucket["key"])
+}
+
+func collectGroupBuckets(container map[string]interface{}, groupings []Grouping, metrics []Metric, level int, keys []string, groupCount *GroupCount) {
+	agg := findAggregation(container, groupName(level))
+	if agg == nil {
+		return
+	}
+
+	buckets, _ := agg["buckets"].([]interface{})
+	for _, rawBucket := range buckets {
+		bucket, ok := rawBucket.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		bucketKeys := append(append([]string{}, keys...), bucketKey(bucket))
+		if level+1 < len(groupings) {
+			collectGroupBuckets(bucket, groupings, metrics, level+1, bucketKeys, groupCount)
+			continue
+		}
+
+		count, _ := bucket["doc_count"].(float64)
+		groupCount.Buckets = append(groupCount.Buckets, GroupBucket{
+			Keys:    bucketKeys,
+			Count:   int(count),
+			Metrics: collectMetrics(bucket, metrics),
+		})
+	}
+
+	if errorUpperBound, _ := agg["doc_count_error_upper_bound"].(float64); int(errorUpperBound) > groupCount.ErrorUpperBound {
+		groupCount.ErrorUpperBound = int(errorUpperBound)
+	}
+
+	if otherDocCount, _ := agg["sum_other_doc_count"].(float64); otherDocCount > 0 {
+		groupCount.Buckets = append(groupCount.Buckets, GroupBucket{
+			Keys:  append(append([]string{}, keys...), OtherGroupKey),
+			Count: int(otherDocCount),
+		})
+		groupCount.OtherDocCount += int(otherDocCount)
+	}
+}
+
+const compositeName = "group_by"
+
+// buildCompositeAggregation builds a composite aggregation with a source for every grouping.
+// All grouping fields must be in the same nested path.
+func buildCompositeAggregation(groupings []Grouping, metrics []Metric, nestedPaths []string, size int, missing string, after map[string]interface{}) (map[string]interface{}, error) {
+	sources := make([]interface{}, len(groupings))
+	var contextPath string
+	for level, grouping := range groupings {
+		fieldPath, _ := getNestedPath(grouping.Field, nestedPaths)
+		if level > 0 && fieldPath != contextPath {
+			return nil, fmt.Errorf("all group fields must be in the same nested path to fetch all buckets")
+		}
+		contextPath = fieldPath
+		sources[level] = map[string]interface{}{
+			groupName(level): grouping.compositeSource(missing),
+		}
+	}
+
+	composite := map[string]interface{}{
+		"size":    size,
+		"sources": sources,
+	}
+	if after != nil {
+		composite["after"] = after
+	}
+
+	agg := map[string]interface{}{
+		"composite": composite,
+	}
+	if len(metrics) > 0 {
+		agg["aggs"] = buildMetricAggregations(metrics, contextPath, nestedPaths)
+	}
+
+	name, wrapped := wrapNested(compositeName, agg, "", contextPath)
+	return map[string]interface{}{name: wrapped}, nil
+}
+
+// collectCompositeBuckets appends the buckets of a composite aggregation page to groupCount
+// and returns the key to fetch the next page after, or nil if this was the last page.
+func collectCompositeBuckets(aggregations map[string]interface{}, groupings []Grouping, metrics []Metric, missing string, groupCount *GroupCount) map[string]interface{} {
+	agg := findAggregation(aggregations, compositeName)
+	if agg == nil {
+		return nil
+	}
+
+	buckets, _ := agg["buckets"].([]interface{})
+	for _, rawBucket := range buckets {
+		bucket, ok := rawBucket.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		key, _ := bucket["key"].(map[string]interface{})
+		keys := make([]string, len(groupings))
+		for level := range groupings {
+			switch value := key[groupName(level)].(type) {
+			case nil:
+				keys[level] = missing
+			case float64:
+				keys[level] = strconv.FormatFloat(value, 'f', -1, 64)
+			default:
+				keys[level] = fmt.Sprint(value)
+			}
+		}
+
+		count, _ := bucket["doc_count"].(float64)
+		groupCount.Buckets = append(groupCount.Buckets, GroupBucket{
+			Keys:    keys,
+			Count:   int(count),
+			Metrics: collectMetrics(bucket, metrics),
+		})
+	}
+
+	afterKey, _ := agg["after_key"].(map[string]interface{})
+	if len(buckets) == 0 {
+		return nil
+	}
+	return afterKey
+}
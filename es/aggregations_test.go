@@ -0,0 +1,315 @@
+package es
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseGrouping(t *testing.T) {
+	tests := []struct {
+		groupingType string
+		input        string
+		expected     Grouping
+		expectError  bool
+	}{
+		{GroupingTerms, "service", Grouping{Field: "service", Type: GroupingTerms}, false},
+		{GroupingHistogram, "price:10", Grouping{Field: "price", Type: GroupingHistogram, Interval: "10"}, false},
+		{GroupingHistogram, "price:ten", Grouping{}, true},
+		{GroupingDateHistogram, "@timestamp:1d", Grouping{Field: "@timestamp", Type: GroupingDateHistogram, Interval: "1d"}, false},
+		{GroupingDateHistogram, "@timestamp", Grouping{}, true},
+		{GroupingDateHistogram, "@timestamp:", Grouping{}, true},
+	}
+
+	for _, test := range tests {
+		grouping, err := ParseGrouping(test.groupingType, test.input)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Expected error for %s %s, but got nil", test.groupingType, test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %s %s: %v", test.groupingType, test.input, err)
+		}
+		if grouping != test.expected {
+			t.Errorf("For %s %s, expected %v, but got %v", test.groupingType, test.input, test.expected, grouping)
+		}
+	}
+}
+
+func TestBuildGroupAggregations(t *testing.T) {
+	groupings := []Grouping{
+		{Field: "service", Type: GroupingTerms},
+		{Field: "comments.user", Type: GroupingTerms},
+		{Field: "@timestamp", Type: GroupingDateHistogram, Interval: "1d"},
+	}
+
+	aggs := buildGroupAggregations(groupings, nil, 0, "", []string{"comments"}, 10, "")
+
+	service := aggs["group_by_0"].(map[string]interface{})
+	nested := service["aggs"].(map[string]interface{})["nested_group_by_1"].(map[string]interface{})
+	if !reflect.DeepEqual(nested["nested"], map[string]interface{}{"path": "comments"}) {
+		t.Fatalf("expected level 1 to be wrapped in a nested aggregation, got %v", nested)
+	}
+
+	user := nested["aggs"].(map[string]interface{})["group_by_1"].(map[string]interface{})
+	reverse := user["aggs"].(map[string]interface{})["reverse_nested_group_by_2"].(map[string]interface{})
+	day := reverse["aggs"].(map[string]interface{})["group_by_2"].(map[string]interface{})
+	expected := map[string]interface{}{"field": "@timestamp", "calendar_interval": "1d"}
+	if !reflect.DeepEqual(day["date_histogram"], expected) {
+		t.Errorf("expected date histogram %v, got %v", expected, day["date_histogram"])
+	}
+}
+
+func TestCollectGroupBuckets(t *testing.T) {
+	response := `{
+		"group_by_0": {"buckets": [
+			{"key": "api", "doc_count": 3, "nested_group_by_1": {"group_by_1": {"buckets": [
+				{"key": 1684108800000, "key_as_string": "2023-05-15T00:00:00.000Z", "doc_count": 2},
+				{"key": 1684195200000, "key_as_string": "2023-05-16T00:00:00.000Z", "doc_count": 1}
+			]}}},
+			{"key": 42, "doc_count": 1, "nested_group_by_1": {"group_by_1": {"buckets": []}}}
+		]}
+	}`
+
+	var aggregations map[string]interface{}
+	if err := json.Unmarshal([]byte(response), &aggregations); err != nil {
+		t.Fatal(err)
+	}
+
+	groupings := []Grouping{
+		{Field: "service", Type: GroupingTerms},
+		{Field: "@timestamp", Type: GroupingDateHistogram, Interval: "1d"},
+	}
+	groupCount := GroupCount{}
+	collectGroupBuckets(aggregations, groupings, nil, 0, nil, &groupCount)
+
+	expected := GroupCount{Buckets: []GroupBucket{
+		{Keys: []string{"api", "2023-05-15T00:00:00.000Z"}, Count: 2, Metrics: map[string]float64{}},
+		{Keys: []string{"api", "2023-05-16T00:00:00.000Z"}, Count: 1, Metrics: map[string]float64{}},
+	}}
+	if !reflect.DeepEqual(groupCount, expected) {
+		t.Errorf("expected %v, got %v", expected, groupCount)
+	}
+}
+
+func TestCollectGroupBucketsInSiblingNestedPaths(t *testing.T) {
+	groupings := []Grouping{
+		{Field: "comments.user", Type: GroupingTerms},
+		{Field: "tags.name", Type: GroupingTerms},
+	}
+
+	aggs := buildGroupAggregations(groupings, nil, 0, "", []string{"comments", "tags"}, 10, "")
+	user := aggs["nested_group_by_0"].(map[string]interface{})["aggs"].(map[string]interface{})["group_by_0"].(map[string]interface{})
+	if _, ok := user["aggs"].(map[string]interface{})["reverse_nested_nested_group_by_1"]; !ok {
+		t.Fatalf("expected level 1 to be wrapped in reverse_nested and nested aggregations, got %v", user["aggs"])
+	}
+
+	response := `{
+		"nested_group_by_0": {"doc_count": 3, "group_by_0": {"buckets": [
+			{"key": "alice", "doc_count": 3, "reverse_nested_nested_group_by_1": {"doc_count": 2, "nested_group_by_1": {"doc_count": 4, "group_by_1": {"buckets": [
+				{"key": "go", "doc_count": 3},
+				{"key": "es", "doc_count": 1}
+			]}}}}
+		]}}
+	}`
+
+	var aggregations map[string]interface{}
+	if err := json.Unmarshal([]byte(response), &aggregations); err != nil {
+		t.Fatal(err)
+	}
+
+	groupCount := GroupCount{}
+	collectGroupBuckets(aggregations, groupings, nil, 0, nil, &groupCount)
+
+	expected := GroupCount{Buckets: []GroupBucket{
+		{Keys: []string{"alice", "go"}, Count: 3, Metrics: map[string]float64{}},
+		{Keys: []string{"alice", "es"}, Count: 1, Metrics: map[string]float64{}},
+	}}
+	if !reflect.DeepEqual(groupCount, expected) {
+		t.Errorf("expected %v, got %v", expected, groupCount)
+	}
+}
+
+func TestParseMetric(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Metric
+		labels      []string
+		expectError bool
+	}{
+		{"sum:price", Metric{Type: MetricSum, Field: "price"}, []string{"sum(price)"}, false},
+		{"cardinality:user.id", Metric{Type: MetricCardinality, Field: "user.id"}, []string{"cardinality(user.id)"}, false},
+		{"percentiles:latency:50,99.9", Metric{Type: MetricPercentiles, Field: "latency", Percents: []float64{50, 99.9}}, []string{"p50(latency)", "p99.9(latency)"}, false},
+		{"percentiles:latency", Metric{}, nil, true},
+		{"percentiles:latency:150", Metric{}, nil, true},
+		{"median:latency", Metric{}, nil, true},
+		{"sum", Metric{}, nil, true},
+	}
+
+	for _, test := range tests {
+		metric, err := ParseMetric(test.input)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Expected error for input %s, but got nil", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for input %s: %v", test.input, err)
+		}
+		if !reflect.DeepEqual(metric, test.expected) {
+			t.Errorf("For input %s, expected %v, but got %v", test.input, test.expected, metric)
+		}
+		if !reflect.DeepEqual(metric.Labels(), test.labels) {
+			t.Errorf("For input %s, expected labels %v, but got %v", test.input, test.labels, metric.Labels())
+		}
+	}
+}
+
+func TestCollectMetrics(t *testing.T) {
+	response := `{
+		"metric_0": {"value": 12.5},
+		"metric_1": {"value": null},
+		"nested_metric_2": {"metric_2": {"values": [{"key": 50.0, "value": 3}, {"key": 99.0, "value": 9}]}}
+	}`
+
+	var bucket map[string]interface{}
+	if err := json.Unmarshal([]byte(response), &bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := []Metric{
+		{Type: MetricAvg, Field: "price"},
+		{Type: MetricMin, Field: "price"},
+		{Type: MetricPercentiles, Field: "comments.length", Percents: []float64{50, 99}},
+	}
+
+	expected := map[string]float64{
+		"avg(price)":           12.5,
+		"p50(comments.length)": 3,
+		"p99(comments.length)": 9,
+	}
+	if values := collectMetrics(bucket, metrics); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestCollectMetricsInSiblingNestedPath(t *testing.T) {
+	metrics := []Metric{
+		{Type: MetricAvg, Field: "comments.length"},
+		{Type: MetricMax, Field: "tags.score"},
+	}
+
+	aggs := buildMetricAggregations(metrics, "comments", []string{"comments", "tags"})
+	if _, ok := aggs["metric_0"]; !ok {
+		t.Errorf("expected the metric in the same nested path not to be wrapped, got %v", aggs)
+	}
+	if _, ok := aggs["reverse_nested_nested_metric_1"]; !ok {
+		t.Fatalf("expected the metric in the sibling nested path to be wrapped twice, got %v", aggs)
+	}
+
+	response := `{
+		"metric_0": {"value": 12.5},
+		"reverse_nested_nested_metric_1": {"doc_count": 2, "nested_metric_1": {"doc_count": 5, "metric_1": {"value": 0.9}}}
+	}`
+
+	var bucket map[string]interface{}
+	if err := json.Unmarshal([]byte(response), &bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]float64{
+		"avg(comments.length)": 12.5,
+		"max(tags.score)":      0.9,
+	}
+	if values := collectMetrics(bucket, metrics); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestCollectGroupBucketsWithOtherDocs(t *testing.T) {
+	response := `{
+		"group_by_0": {"doc_count_error_upper_bound": 4, "sum_other_doc_count": 7, "buckets": [
+			{"key": "api", "doc_count": 3, "group_by_1": {"doc_count_error_upper_bound": 0, "sum_other_doc_count": 1, "buckets": [
+				{"key": "GET", "doc_count": 2}
+			]}}
+		]}
+	}`
+
+	var aggregations map[string]interface{}
+	if err := json.Unmarshal([]byte(response), &aggregations); err != nil {
+		t.Fatal(err)
+	}
+
+	groupings := []Grouping{
+		{Field: "service", Type: GroupingTerms},
+		{Field: "method", Type: GroupingTerms},
+	}
+	groupCount := GroupCount{}
+	collectGroupBuckets(aggregations, groupings, nil, 0, nil, &groupCount)
+
+	expected := GroupCount{
+		Buckets: []GroupBucket{
+			{Keys: []string{"api", "GET"}, Count: 2, Metrics: map[string]float64{}},
+			{Keys: []string{"api", OtherGroupKey}, Count: 1},
+			{Keys: []string{OtherGroupKey}, Count: 7},
+		},
+		OtherDocCount:   8,
+		ErrorUpperBound: 4,
+	}
+	if !reflect.DeepEqual(groupCount, expected) {
+		t.Errorf("expected %v, got %v", expected, groupCount)
+	}
+}
+
+func TestCompositeAggregation(t *testing.T) {
+	groupings := []Grouping{
+		{Field: "service", Type: GroupingTerms},
+		{Field: "@timestamp", Type: GroupingDateHistogram, Interval: "1d"},
+	}
+
+	aggs, err := buildCompositeAggregation(groupings, nil, nil, 100, "N/A", map[string]interface{}{"group_by_0": "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	composite := aggs["group_by"].(map[string]interface{})["composite"].(map[string]interface{})
+	sources := composite["sources"].([]interface{})
+	service := sources[0].(map[string]interface{})["group_by_0"].(map[string]interface{})["terms"].(map[string]interface{})
+	if !reflect.DeepEqual(service, map[string]interface{}{"field": "service", "missing_bucket": true}) {
+		t.Errorf("unexpected terms source: %v", service)
+	}
+	if composite["after"] == nil {
+		t.Errorf("expected the after key to be set")
+	}
+
+	if _, err := buildCompositeAggregation([]Grouping{{Field: "comments.user", Type: GroupingTerms}, {Field: "service", Type: GroupingTerms}}, nil, []string{"comments"}, 100, "", nil); err == nil {
+		t.Errorf("expected an error for group fields in different nested paths")
+	}
+
+	response := `{
+		"group_by": {"after_key": {"group_by_0": null, "group_by_1": "2023-05-16T00:00:00.000Z"}, "buckets": [
+			{"key": {"group_by_0": "api", "group_by_1": "2023-05-15T00:00:00.000Z"}, "doc_count": 2},
+			{"key": {"group_by_0": null, "group_by_1": "2023-05-16T00:00:00.000Z"}, "doc_count": 1}
+		]}
+	}`
+
+	var aggregations map[string]interface{}
+	if err := json.Unmarshal([]byte(response), &aggregations); err != nil {
+		t.Fatal(err)
+	}
+
+	groupCount := GroupCount{}
+	after := collectCompositeBuckets(aggregations, groupings, nil, "N/A", &groupCount)
+	if after == nil {
+		t.Errorf("expected an after key")
+	}
+	expected := []GroupBucket{
+		{Keys: []string{"api", "2023-05-15T00:00:00.000Z"}, Count: 2, Metrics: map[string]float64{}},
+		{Keys: []string{"N/A", "2023-05-16T00:00:00.000Z"}, Count: 1, Metrics: map[string]float64{}},
+	}
+	if !reflect.DeepEqual(groupCount.Buckets, expected) {
+		t.Errorf("expected %v, got %v", expected, groupCount.Buckets)
+	}
+}
@@ -0,0 +1,46 @@
+package es
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type BulkItemError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+type BulkItemResult struct {
+	Index  string         `json:"_index"`
+	ID     string         `json:"_id"`
+	Status int            `json:"status"`
+	Error  *BulkItemError `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	Took   int                         `json:"took"`
+	Errors bool                        `json:"errors"`
+	Items  []map[string]BulkItemResult `json:"items"`
+}
+
+// Results returns the result of every item in the order of the request,
+// regardless of the operation type.
+func (r BulkResponse) Results() []BulkItemResult {
+	results := make([]BulkItemResult, 0, len(r.Items))
+	for _, item := range r.Items {
+		for _, result := range item {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// Bulk sends an NDJSON body of actions and documents to the _bulk endpoint of the index.
+func Bulk(index string, body []byte) (BulkResponse, error) {
+	endpoint := fmt.Sprintf("%s/_bulk", index)
+	var response BulkResponse
+	if err := rawHttpRequest(http.MethodPost, endpoint, "application/x-ndjson", body, &response, http.StatusOK); err != nil {
+		return BulkResponse{}, err
+	}
+	return response, nil
+}
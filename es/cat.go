@@ -41,6 +41,38 @@ func GetNodes(nodeName string) ([]Node, error) {
 	return nodes, nil
 }
 
+// NodeDisk is the disk usage of a node in bytes.
+type NodeDisk struct {
+	Name      string `json:"name"`
+	DiskTotal string `json:"disk.total"`
+	DiskUsed  string `json:"disk.used"`
+}
+
+// GetNodeDisks returns the disk usage of the nodes in bytes rather than rounded to a unit.
+func GetNodeDisks() ([]NodeDisk, error) {
+	var disks []NodeDisk
+	if err := getJSONResponse("_cat/nodes?format=json&bytes=b&h=name,disk.total,disk.used", &disks); err != nil {
+		return nil, err
+	}
+	return disks, nil
+}
+
+// IndexStore is the store size of an index in bytes.
+type IndexStore struct {
+	Index     string `json:"index"`
+	StoreSize string `json:"store.size"`
+}
+
+// GetIndexStores returns the store size of the indices in bytes rather than rounded to a
+// unit.
+func GetIndexStores() ([]IndexStore, error) {
+	var stores []IndexStore
+	if err := getJSONResponse("_cat/indices?format=json&bytes=b&h=index,store.size", &stores); err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
 type Index struct {
 	Health       string `json:"health"`
 	Status       string `json:"status"`
@@ -105,6 +137,21 @@ func GetShards(index string) ([]Shard, error) {
 	return shards, nil
 }
 
+type NodeAttribute struct {
+	Node  string `json:"node"`
+	Attr  string `json:"attr"`
+	Value string `json:"value"`
+}
+
+func GetNodeAttributes() ([]NodeAttribute, error) {
+	var attributes []NodeAttribute
+	if err := getJSONResponse("_cat/nodeattrs?format=json&h=node,attr,value", &attributes); err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
 type NodeDetails map[string]NodeSummary
 
 type NodeSummary struct {
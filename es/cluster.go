@@ -1,5 +1,12 @@
 package es
 
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
 type ClusterHealth struct {
 	ClusterName                 string  `json:"cluster_name" yaml:"clusterName"`
 	Status                      string  `json:"status" yaml:"status"`
@@ -81,3 +88,74 @@ func GetCluster() (*Cluster, error) {
 
 	return &cluster, nil
 }
+
+func GetClusterHealth() (ClusterHealth, error) {
+	var health ClusterHealth
+	if err := getJSONResponse("_cluster/health", &health); err != nil {
+		return ClusterHealth{}, err
+	}
+
+	return health, nil
+}
+
+// WaitForClusterHealth blocks until the cluster, or the given index, meets all of the
+// given conditions or the timeout expires. The TimedOut field of the returned health
+// reports whether the conditions were met.
+func WaitForClusterHealth(index, status string, noRelocating, noInitializing, allActive bool, timeout time.Duration) (ClusterHealth, error) {
+	endpoint := "_cluster/health"
+	if index != "" {
+		endpoint += "/" + index
+	}
+
+	values := url.Values{
+		"timeout": {fmt.Sprintf("%dms", timeout.Milliseconds())},
+	}
+	if status != "" {
+		values.Set("wait_for_status", status)
+	}
+	if noRelocating {
+		values.Set("wait_for_no_relocating_shards", "true")
+	}
+	if noInitializing {
+		values.Set("wait_for_no_initializing_shards", "true")
+	}
+	if allActive {
+		values.Set("wait_for_active_shards", "all")
+	}
+
+	var health ClusterHealth
+	err := httpRequest(http.MethodGet, endpoint+"?"+values.Encode(), nil, &health, http.StatusOK, http.StatusRequestTimeout)
+	if err != nil {
+		return ClusterHealth{}, err
+	}
+
+	return health, nil
+}
+
+type FlatClusterSettings struct {
+	Persistent map[string]interface{} `json:"persistent"`
+	Transient  map[string]interface{} `json:"transient"`
+	Defaults   map[string]interface{} `json:"defaults"`
+}
+
+// GetFlatClusterSettings returns the cluster settings keyed by their full dotted names,
+// including the defaults of the settings that are not set.
+func GetFlatClusterSettings() (FlatClusterSettings, error) {
+	var settings FlatClusterSettings
+	if err := getJSONResponse("_cluster/settings?flat_settings=true&include_defaults=true", &settings); err != nil {
+		return FlatClusterSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// Get returns the effective value of a setting: transient settings take precedence over
+// persistent ones, which take precedence over the defaults.
+func (s FlatClusterSettings) Get(key string) (string, bool) {
+	for _, settings := range []map[string]interface{}{s.Transient, s.Persistent, s.Defaults} {
+		if value, ok := settings[key]; ok {
+			return fmt.Sprint(value), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,88 @@
+package es
+
+import "sort"
+
+type Deprecation struct {
+	Level                       string `json:"level" yaml:"level"`
+	Message                     string `json:"message" yaml:"message"`
+	URL                         string `json:"url" yaml:"url"`
+	Details                     string `json:"details,omitempty" yaml:"details,omitempty"`
+	ResolveDuringRollingUpgrade bool   `json:"resolve_during_rolling_upgrade" yaml:"resolveDuringRollingUpgrade"`
+}
+
+type DeprecationsResponse struct {
+	ClusterSettings []Deprecation            `json:"cluster_settings"`
+	NodeSettings    []Deprecation            `json:"node_settings"`
+	IndexSettings   map[string][]Deprecation `json:"index_settings"`
+	MLSettings      []Deprecation            `json:"ml_settings"`
+}
+
+// DeprecationGroup holds the deprecation issues of a single level.
+type DeprecationGroup struct {
+	Cluster []Deprecation            `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	Nodes   []Deprecation            `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+	Indices map[string][]Deprecation `json:"indices,omitempty" yaml:"indices,omitempty"`
+	ML      []Deprecation            `json:"ml,omitempty" yaml:"ml,omitempty"`
+}
+
+// DeprecationLevels are the levels of the deprecation issues from the most to the least
+// severe.
+var DeprecationLevels = []string{"critical", "warning", "info", "none"}
+
+// GetDeprecations returns the deprecation issues of the cluster, the nodes and the indices
+// matching the given pattern.
+func GetDeprecations(index string) (DeprecationsResponse, error) {
+	endpoint := "_migration/deprecations"
+	if index != "" {
+		endpoint = index + "/" + endpoint
+	}
+
+	var response DeprecationsResponse
+	if err := getJSONResponse(endpoint, &response); err != nil {
+		return DeprecationsResponse{}, err
+	}
+
+	return response, nil
+}
+
+// GroupByLevel groups the deprecation issues by their level.
+func (r DeprecationsResponse) GroupByLevel() map[string]*DeprecationGroup {
+	groups := make(map[string]*DeprecationGroup)
+	group := func(level string) *DeprecationGroup {
+		if _, ok := groups[level]; !ok {
+			groups[level] = &DeprecationGroup{}
+		}
+		return groups[level]
+	}
+
+	for _, deprecation := range r.ClusterSettings {
+		g := group(deprecation.Level)
+		g.Cluster = append(g.Cluster, deprecation)
+	}
+	for _, deprecation := range r.NodeSettings {
+		g := group(deprecation.Level)
+		g.Nodes = append(g.Nodes, deprecation)
+	}
+	for _, deprecation := range r.MLSettings {
+		g := group(deprecation.Level)
+		g.ML = append(g.ML, deprecation)
+	}
+
+	indices := make([]string, 0, len(r.IndexSettings))
+	for index := range r.IndexSettings {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+
+	for _, index := range indices {
+		for _, deprecation := range r.IndexSettings[index] {
+			g := group(deprecation.Level)
+			if g.Indices == nil {
+				g.Indices = make(map[string][]Deprecation)
+			}
+			g.Indices[index] = append(g.Indices[index], deprecation)
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,45 @@
+package es
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupByLevel(t *testing.T) {
+	critical := Deprecation{Level: "critical", Message: "zen discovery"}
+	warning := Deprecation{Level: "warning", Message: "translog retention"}
+	info := Deprecation{Level: "info", Message: "ml"}
+
+	response := DeprecationsResponse{
+		ClusterSettings: []Deprecation{critical},
+		NodeSettings:    []Deprecation{warning, critical},
+		IndexSettings: map[string][]Deprecation{
+			"logs":     {warning},
+			"articles": {critical, warning},
+		},
+		MLSettings: []Deprecation{info},
+	}
+
+	expected := map[string]*DeprecationGroup{
+		"critical": {
+			Cluster: []Deprecation{critical},
+			Nodes:   []Deprecation{critical},
+			Indices: map[string][]Deprecation{"articles": {critical}},
+		},
+		"warning": {
+			Nodes:   []Deprecation{warning},
+			Indices: map[string][]Deprecation{"articles": {warning}, "logs": {warning}},
+		},
+		"info": {
+			ML: []Deprecation{info},
+		},
+	}
+
+	groups := response.GroupByLevel()
+	if !reflect.DeepEqual(groups, expected) {
+		for level, group := range groups {
+			t.Logf("%s: %+v", level, *group)
+		}
+		t.Errorf("unexpected groups")
+	}
+}
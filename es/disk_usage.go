@@ -0,0 +1,81 @@
+package es
+
+import "encoding/json"
+
+// FieldDiskUsage is the number of bytes a field takes on disk, in total and per data
+// structure.
+type FieldDiskUsage struct {
+	Total         int64
+	InvertedIndex int64
+	StoredFields  int64
+	DocValues     int64
+	Points        int64
+	Norms         int64
+	TermVectors   int64
+	KnnVectors    int64
+}
+
+// DiskUsage is the disk usage of the indices matching a pattern, summed over the indices.
+type DiskUsage struct {
+	StoreSize int64
+	Fields    map[string]FieldDiskUsage
+}
+
+type fieldDiskUsage struct {
+	TotalInBytes  int64 `json:"total_in_bytes"`
+	InvertedIndex struct {
+		TotalInBytes int64 `json:"total_in_bytes"`
+	} `json:"inverted_index"`
+	StoredFieldsInBytes int64 `json:"stored_fields_in_bytes"`
+	DocValuesInBytes    int64 `json:"doc_values_in_bytes"`
+	PointsInBytes       int64 `json:"points_in_bytes"`
+	NormsInBytes        int64 `json:"norms_in_bytes"`
+	TermVectorsInBytes  int64 `json:"term_vectors_in_bytes"`
+	KnnVectorsInBytes   int64 `json:"knn_vectors_in_bytes"`
+}
+
+type indexDiskUsage struct {
+	StoreSizeInBytes int64                     `json:"store_size_in_bytes"`
+	Fields           map[string]fieldDiskUsage `json:"fields"`
+}
+
+// AnalyzeDiskUsage analyzes the disk usage of every field of the indices matching the
+// given pattern. The analysis reads every shard and is expensive.
+func AnalyzeDiskUsage(index string) (DiskUsage, error) {
+	var response map[string]json.RawMessage
+	if err := postWithoutBody(index+"/_disk_usage?run_expensive_tasks=true", &response); err != nil {
+		return DiskUsage{}, err
+	}
+
+	return aggregateDiskUsage(response)
+}
+
+func aggregateDiskUsage(response map[string]json.RawMessage) (DiskUsage, error) {
+	usage := DiskUsage{Fields: make(map[string]FieldDiskUsage)}
+	for name, raw := range response {
+		if name == "_shards" {
+			continue
+		}
+
+		var indexUsage indexDiskUsage
+		if err := json.Unmarshal(raw, &indexUsage); err != nil {
+			return DiskUsage{}, err
+		}
+
+		usage.StoreSize += indexUsage.StoreSizeInBytes
+		for field, fieldUsage := range indexUsage.Fields {
+			total := usage.Fields[field]
+			total.Total += fieldUsage.TotalInBytes
+			total.InvertedIndex += fieldUsage.InvertedIndex.TotalInBytes
+			total.StoredFields += fieldUsage.StoredFieldsInBytes
+			total.DocValues += fieldUsage.DocValuesInBytes
+			total.Points += fieldUsage.PointsInBytes
+			total.Norms += fieldUsage.NormsInBytes
+			total.TermVectors += fieldUsage.TermVectorsInBytes
+			total.KnnVectors += fieldUsage.KnnVectorsInBytes
+			usage.Fields[field] = total
+		}
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,48 @@
+package es
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAggregateDiskUsage(t *testing.T) {
+	body := `{
+		"_shards": {"total": 2, "successful": 2, "failed": 0},
+		"logs-1": {
+			"store_size": "1kb",
+			"store_size_in_bytes": 1000,
+			"fields": {
+				"message": {"total": "600b", "total_in_bytes": 600, "inverted_index": {"total": "400b", "total_in_bytes": 400}, "stored_fields_in_bytes": 100, "norms_in_bytes": 100},
+				"status": {"total_in_bytes": 50, "doc_values_in_bytes": 30, "points_in_bytes": 20}
+			}
+		},
+		"logs-2": {
+			"store_size_in_bytes": 500,
+			"fields": {
+				"message": {"total_in_bytes": 300, "inverted_index": {"total_in_bytes": 300}}
+			}
+		}
+	}`
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	usage, err := aggregateDiskUsage(response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := DiskUsage{
+		StoreSize: 1500,
+		Fields: map[string]FieldDiskUsage{
+			"message": {Total: 900, InvertedIndex: 700, StoredFields: 100, Norms: 100},
+			"status":  {Total: 50, DocValues: 30, Points: 20},
+		},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected usage %+v, got %+v", expected, usage)
+	}
+}
@@ -0,0 +1,220 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type SearchHit struct {
+	Index  string            `json:"_index"`
+	ID     string            `json:"_id"`
+	Score  *float64          `json:"_score"`
+	Source json.RawMessage   `json:"_source"`
+	Sort   []json.RawMessage `json:"sort,omitempty"`
+}
+
+type SearchHitsResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	PitID    string `json:"pit_id"`
+	Hits     struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []SearchHit `json:"hits"`
+	} `json:"hits"`
+}
+
+type VersionResponse struct {
+	Version struct {
+		Number string `json:"number"`
+	} `json:"version"`
+}
+
+func GetVersion() (string, error) {
+	var response VersionResponse
+	if err := getJSONResponse("", &response); err != nil {
+		return "", err
+	}
+	return response.Version.Number, nil
+}
+
+// SupportsPointInTime reports whether the cluster supports paging a point in time with
+// the _shard_doc tiebreaker sort, which was introduced in Elasticsearch 7.12. Point in
+// time searches exist since 7.10 but cannot be sorted on _shard_doc before 7.12.
+func SupportsPointInTime() (bool, error) {
+	version, err := GetVersion()
+	if err != nil {
+		return false, err
+	}
+	return supportsPointInTime(version)
+}
+
+func supportsPointInTime(version string) (bool, error) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false, fmt.Errorf("unexpected version: %s", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false, fmt.Errorf("unexpected version: %s", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, fmt.Errorf("unexpected version: %s", version)
+	}
+
+	return major > 7 || (major == 7 && minor >= 12), nil
+}
+
+type PointInTimeResponse struct {
+	ID string `json:"id"`
+}
+
+func OpenPointInTime(index, keepAlive string) (string, error) {
+	endpoint := fmt.Sprintf("%s/_pit?keep_alive=%s", index, url.QueryEscape(keepAlive))
+	var response PointInTimeResponse
+	if err := postWithoutBody(endpoint, &response); err != nil {
+		return "", err
+	}
+	return response.ID, nil
+}
+
+func ClosePointInTime(id string) error {
+	body := map[string]interface{}{"id": id}
+	var response JsonResponse
+	return httpRequest(http.MethodDelete, "_pit", body, &response, http.StatusOK)
+}
+
+func buildExportQuery(terms []string, nestedPaths []string) (map[string]interface{}, error) {
+	filters, err := buildTermFilters(nil, terms, nestedPaths)
+	if err != nil {
+		return nil, err
+	}
+	if len(filters) == 0 {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"filter": filters,
+		},
+	}, nil
+}
+
+func buildExportBody(terms []string, nestedPaths []string, slice, slices, size int) (JsonResponse, error) {
+	query, err := buildExportQuery(terms, nestedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	body := JsonResponse{
+		"query": query,
+		"size":  size,
+	}
+	if slices > 1 {
+		body["slice"] = map[string]interface{}{
+			"id":  slice,
+			"max": slices,
+		}
+	}
+	return body, nil
+}
+
+// SearchPointInTime fetches the next page of a slice of a point in time. The first page is
+// requested with a nil searchAfter and also tracks the total number of hits of the slice.
+func SearchPointInTime(
+	pitID string,
+	keepAlive string,
+	terms []string,
+	nestedPaths []string,
+	slice int,
+	slices int,
+	size int,
+	searchAfter []json.RawMessage,
+) (SearchHitsResponse, error) {
+	body, err := buildPointInTimeBody(pitID, keepAlive, terms, nestedPaths, slice, slices, size, searchAfter)
+	if err != nil {
+		return SearchHitsResponse{}, err
+	}
+
+	var response SearchHitsResponse
+	if err := getJSONResponseWithBody("_search", &response, body); err != nil {
+		return SearchHitsResponse{}, err
+	}
+	return response, nil
+}
+
+func buildPointInTimeBody(
+	pitID string,
+	keepAlive string,
+	terms []string,
+	nestedPaths []string,
+	slice int,
+	slices int,
+	size int,
+	searchAfter []json.RawMessage,
+) (JsonResponse, error) {
+	body, err := buildExportBody(terms, nestedPaths, slice, slices, size)
+	if err != nil {
+		return nil, err
+	}
+
+	body["pit"] = map[string]interface{}{
+		"id":         pitID,
+		"keep_alive": keepAlive,
+	}
+	body["sort"] = []interface{}{map[string]string{"_shard_doc": "asc"}}
+	if searchAfter != nil {
+		body["search_after"] = searchAfter
+		body["track_total_hits"] = false
+	} else {
+		body["track_total_hits"] = true
+	}
+	return body, nil
+}
+
+// StartScroll opens a scroll over a slice of the index and returns its first page.
+func StartScroll(
+	index string,
+	keepAlive string,
+	terms []string,
+	nestedPaths []string,
+	slice int,
+	slices int,
+	size int,
+) (SearchHitsResponse, error) {
+	body, err := buildExportBody(terms, nestedPaths, slice, slices, size)
+	if err != nil {
+		return SearchHitsResponse{}, err
+	}
+	body["sort"] = []string{"_doc"}
+
+	endpoint := fmt.Sprintf("%s/_search?scroll=%s", index, url.QueryEscape(keepAlive))
+	var response SearchHitsResponse
+	if err := getJSONResponseWithBody(endpoint, &response, body); err != nil {
+		return SearchHitsResponse{}, err
+	}
+	return response, nil
+}
+
+func ContinueScroll(scrollID, keepAlive string) (SearchHitsResponse, error) {
+	body := map[string]interface{}{
+		"scroll":    keepAlive,
+		"scroll_id": scrollID,
+	}
+
+	var response SearchHitsResponse
+	if err := getJSONResponseWithBody("_search/scroll", &response, body); err != nil {
+		return SearchHitsResponse{}, err
+	}
+	return response, nil
+}
+
+func ClearScroll(scrollID string) error {
+	body := map[string]interface{}{"scroll_id": scrollID}
+	var response JsonResponse
+	return httpRequest(http.MethodDelete, "_search/scroll", body, &response, http.StatusOK)
+}
@@ -0,0 +1,91 @@
+package es
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSupportsPointInTime(t *testing.T) {
+	tests := []struct {
+		version     string
+		expected    bool
+		expectError bool
+	}{
+		{"6.8.23", false, false},
+		{"7.10.2", false, false},
+		{"7.11.1", false, false},
+		{"7.12.0", true, false},
+		{"7.17.9", true, false},
+		{"8.0.0", true, false},
+		{"8.11.0-SNAPSHOT", true, false},
+		{"8", false, true},
+		{"x.y.z", false, true},
+	}
+
+	for _, test := range tests {
+		supported, err := supportsPointInTime(test.version)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Expected error for version %s, but got nil", test.version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for version %s: %v", test.version, err)
+		}
+		if supported != test.expected {
+			t.Errorf("Version %s: expected %v, got %v", test.version, test.expected, supported)
+		}
+	}
+}
+
+func TestBuildPointInTimeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		terms       []string
+		slice       int
+		slices      int
+		searchAfter []json.RawMessage
+		expected    string
+	}{
+		{
+			name:     "first page",
+			slices:   1,
+			expected: `{"pit":{"id":"p1","keep_alive":"5m"},"query":{"match_all":{}},"size":100,"sort":[{"_shard_doc":"asc"}],"track_total_hits":true}`,
+		},
+		{
+			name:        "next page",
+			slices:      1,
+			searchAfter: []json.RawMessage{json.RawMessage(`42`)},
+			expected:    `{"pit":{"id":"p1","keep_alive":"5m"},"query":{"match_all":{}},"search_after":[42],"size":100,"sort":[{"_shard_doc":"asc"}],"track_total_hits":false}`,
+		},
+		{
+			name:        "sliced with terms",
+			terms:       []string{"status:published"},
+			slice:       1,
+			slices:      4,
+			searchAfter: []json.RawMessage{json.RawMessage(`7`)},
+			expected:    `{"pit":{"id":"p1","keep_alive":"5m"},"query":{"bool":{"filter":[{"term":{"status":"published"}}]}},"search_after":[7],"size":100,"slice":{"id":1,"max":4},"sort":[{"_shard_doc":"asc"}],"track_total_hits":false}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := buildPointInTimeBody("p1", "5m", test.terms, nil, test.slice, test.slices, 100, test.searchAfter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			actual, err := json.Marshal(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+
+	if _, err := buildPointInTimeBody("p1", "5m", []string{"invalid"}, nil, 0, 1, 100, nil); err == nil {
+		t.Error("Expected error for an invalid term, but got nil")
+	}
+}
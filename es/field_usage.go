@@ -0,0 +1,107 @@
+package es
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// FieldUsage counts how many times the data structures of a field were accessed by
+// searches since the shards were opened.
+type FieldUsage struct {
+	Any           int64
+	InvertedIndex int64
+	StoredFields  int64
+	DocValues     int64
+	Points        int64
+	Norms         int64
+	TermVectors   int64
+	KnnVectors    int64
+}
+
+type fieldUsageStats struct {
+	Any           int64 `json:"any"`
+	InvertedIndex struct {
+		Terms int64 `json:"terms"`
+	} `json:"inverted_index"`
+	StoredFields int64 `json:"stored_fields"`
+	DocValues    int64 `json:"doc_values"`
+	Points       int64 `json:"points"`
+	Norms        int64 `json:"norms"`
+	TermVectors  int64 `json:"term_vectors"`
+	KnnVectors   int64 `json:"knn_vectors"`
+}
+
+type indexFieldUsage struct {
+	Shards []struct {
+		Stats struct {
+			Fields map[string]fieldUsageStats `json:"fields"`
+		} `json:"stats"`
+	} `json:"shards"`
+}
+
+// GetFieldUsage returns the usage of every field of the indices matching the given
+// pattern, summed over all their shards.
+func GetFieldUsage(index string) (map[string]FieldUsage, error) {
+	var response map[string]json.RawMessage
+	if err := getJSONResponse(index+"/_field_usage_stats", &response); err != nil {
+		return nil, err
+	}
+
+	return aggregateFieldUsage(response)
+}
+
+func aggregateFieldUsage(response map[string]json.RawMessage) (map[string]FieldUsage, error) {
+	usage := make(map[string]FieldUsage)
+	for name, raw := range response {
+		if name == "_shards" {
+			continue
+		}
+
+		var indexUsage indexFieldUsage
+		if err := json.Unmarshal(raw, &indexUsage); err != nil {
+			return nil, err
+		}
+
+		for _, shard := range indexUsage.Shards {
+			for field, stats := range shard.Stats.Fields {
+				total := usage[field]
+				total.Any += stats.Any
+				total.InvertedIndex += stats.InvertedIndex.Terms
+				total.StoredFields += stats.StoredFields
+				total.DocValues += stats.DocValues
+				total.Points += stats.Points
+				total.Norms += stats.Norms
+				total.TermVectors += stats.TermVectors
+				total.KnnVectors += stats.KnnVectors
+				usage[field] = total
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// UnusedFields returns the fields of the mappings of any of the indices that were never
+// accessed, sorted by path. A field mapped with different types is listed once per type.
+func UnusedFields(fields map[string][]Field, usage map[string]FieldUsage) []Field {
+	seen := make(map[string]bool)
+	var unused []Field
+	for _, indexFields := range fields {
+		for _, field := range indexFields {
+			key := field.Path + "\x00" + field.Type
+			if usage[field.Path].Any > 0 || seen[key] {
+				continue
+			}
+			seen[key] = true
+			unused = append(unused, field)
+		}
+	}
+
+	sort.Slice(unused, func(i, j int) bool {
+		if unused[i].Path != unused[j].Path {
+			return unused[i].Path < unused[j].Path
+		}
+		return unused[i].Type < unused[j].Type
+	})
+	return unused
+}
@@ -0,0 +1,66 @@
+package es
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAggregateFieldUsage(t *testing.T) {
+	body := `{
+		"_shards": {"total": 2, "successful": 2, "failed": 0},
+		"logs-1": {"shards": [
+			{"stats": {"fields": {
+				"message": {"any": 3, "inverted_index": {"terms": 3, "postings": 3}, "norms": 2},
+				"status": {"any": 1, "doc_values": 1}
+			}}},
+			{"stats": {"fields": {
+				"message": {"any": 2, "inverted_index": {"terms": 2}, "stored_fields": 1}
+			}}}
+		]},
+		"logs-2": {"shards": [
+			{"stats": {"fields": {
+				"status": {"any": 4, "inverted_index": {"terms": 1}, "doc_values": 3, "points": 1, "knn_vectors": 0}
+			}}}
+		]}
+	}`
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	usage, err := aggregateFieldUsage(response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]FieldUsage{
+		"message": {Any: 5, InvertedIndex: 5, StoredFields: 1, Norms: 2},
+		"status":  {Any: 5, InvertedIndex: 1, DocValues: 4, Points: 1},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected usage %+v, got %+v", expected, usage)
+	}
+}
+
+func TestUnusedFields(t *testing.T) {
+	fields := map[string][]Field{
+		"logs-1": {{Path: "message", Type: "text"}, {Path: "status", Type: "keyword"}, {Path: "trace", Type: "keyword"}},
+		"logs-2": {{Path: "status", Type: "integer"}, {Path: "trace", Type: "keyword"}, {Path: "user", Type: "keyword"}},
+	}
+	usage := map[string]FieldUsage{
+		"message": {Any: 5},
+		"status":  {Any: 0, DocValues: 0},
+	}
+
+	expected := []Field{
+		{Path: "status", Type: "integer"},
+		{Path: "status", Type: "keyword"},
+		{Path: "trace", Type: "keyword"},
+		{Path: "user", Type: "keyword"},
+	}
+	if unused := UnusedFields(fields, usage); !reflect.DeepEqual(unused, expected) {
+		t.Errorf("expected unused fields %+v, got %+v", expected, unused)
+	}
+}
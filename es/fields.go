@@ -0,0 +1,168 @@
+package es
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Field is a field of the mappings of an index. Multi-fields are fields of their own,
+// e.g. title.raw.
+type Field struct {
+	Path        string
+	Type        string
+	NestedPath  string
+	Indexed     bool
+	DocValues   bool
+	Analyzer    string
+	MultiFields []string
+}
+
+// typesWithoutDocValues are the field types that have no doc values by default.
+var typesWithoutDocValues = map[string]bool{
+	"text":               true,
+	"match_only_text":    true,
+	"annotated_text":     true,
+	"search_as_you_type": true,
+	"completion":         true,
+	"binary":             true,
+	"alias":              true,
+	"percolator":         true,
+	"rank_feature":       true,
+	"rank_features":      true,
+	"sparse_vector":      true,
+}
+
+// analyzedTypes are the field types that are analyzed with the standard analyzer unless
+// another one is set.
+var analyzedTypes = map[string]bool{
+	"text":               true,
+	"match_only_text":    true,
+	"annotated_text":     true,
+	"search_as_you_type": true,
+}
+
+// GetFields returns the fields of the indices matching the given pattern keyed by index
+// name.
+func GetFields(index string) (map[string][]Field, error) {
+	mappings, err := GetMappings(index)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string][]Field, len(mappings))
+	for name, indexMappings := range mappings {
+		fields[name] = FlattenMappings(indexMappings)
+	}
+
+	return fields, nil
+}
+
+// FlattenMappings returns the leaf fields of the mappings sorted by path. Object and nested
+// fields are not listed themselves, the fields inside nested ones have their NestedPath set.
+func FlattenMappings(mappings interface{}) []Field {
+	var fields []Field
+	if m, ok := mappings.(map[string]interface{}); ok {
+		flattenProperties(m["properties"], "", "", &fields)
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+	return fields
+}
+
+func flattenProperties(properties interface{}, parent, nestedPath string, fields *[]Field) {
+	props, ok := properties.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name, value := range props {
+		definition, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		path := name
+		if parent != "" {
+			path = parent + "." + name
+		}
+
+		fieldType, _ := definition["type"].(string)
+		switch {
+		case fieldType == "nested":
+			flattenProperties(definition["properties"], path, path, fields)
+			continue
+		case fieldType == "object" || fieldType == "":
+			flattenProperties(definition["properties"], path, nestedPath, fields)
+			continue
+		}
+
+		field := newField(path, nestedPath, definition)
+
+		multiFields, _ := definition["fields"].(map[string]interface{})
+		for multiName, multiValue := range multiFields {
+			multiDefinition, ok := multiValue.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			field.MultiFields = append(field.MultiFields, multiName)
+			*fields = append(*fields, newField(path+"."+multiName, nestedPath, multiDefinition))
+		}
+		sort.Strings(field.MultiFields)
+
+		*fields = append(*fields, field)
+	}
+}
+
+func newField(path, nestedPath string, definition map[string]interface{}) Field {
+	fieldType, _ := definition["type"].(string)
+
+	field := Field{
+		Path:       path,
+		Type:       fieldType,
+		NestedPath: nestedPath,
+		Indexed:    fieldType != "binary" && fieldType != "alias",
+		DocValues:  !typesWithoutDocValues[fieldType],
+	}
+	if value, ok := definition["index"]; ok {
+		field.Indexed = fmt.Sprint(value) != "false"
+	}
+	if value, ok := definition["doc_values"]; ok {
+		field.DocValues = fmt.Sprint(value) != "false"
+	}
+
+	if analyzer, ok := definition["analyzer"].(string); ok {
+		field.Analyzer = analyzer
+	} else if analyzedTypes[fieldType] {
+		field.Analyzer = "standard"
+	}
+
+	return field
+}
+
+// FieldTypeConflicts returns the fields mapped with different types across the indices,
+// keyed by field path and type, with the indices mapping the field with that type.
+func FieldTypeConflicts(fields map[string][]Field) map[string]map[string][]string {
+	types := make(map[string]map[string][]string)
+	for index, indexFields := range fields {
+		for _, field := range indexFields {
+			if types[field.Path] == nil {
+				types[field.Path] = make(map[string][]string)
+			}
+			types[field.Path][field.Type] = append(types[field.Path][field.Type], index)
+		}
+	}
+
+	conflicts := make(map[string]map[string][]string)
+	for path, indicesByType := range types {
+		if len(indicesByType) < 2 {
+			continue
+		}
+		for _, indices := range indicesByType {
+			sort.Strings(indices)
+		}
+		conflicts[path] = indicesByType
+	}
+	return conflicts
+}
@@ -0,0 +1,70 @@
+package es
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlattenMappings(t *testing.T) {
+	mappings := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"title": map[string]interface{}{
+				"type":     "text",
+				"analyzer": "english",
+				"fields": map[string]interface{}{
+					"raw": map[string]interface{}{"type": "keyword"},
+				},
+			},
+			"body": map[string]interface{}{"type": "text", "index": false},
+			"user": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{"type": "keyword", "doc_values": false},
+					"age":  map[string]interface{}{"type": "integer"},
+				},
+			},
+			"comments": map[string]interface{}{
+				"type": "nested",
+				"properties": map[string]interface{}{
+					"text":   map[string]interface{}{"type": "text"},
+					"author": map[string]interface{}{"properties": map[string]interface{}{"id": map[string]interface{}{"type": "long"}}},
+				},
+			},
+			"payload": map[string]interface{}{"type": "binary"},
+			"meta":    map[string]interface{}{"type": "object", "enabled": false},
+		},
+	}
+
+	expected := []Field{
+		{Path: "body", Type: "text", Indexed: false, Analyzer: "standard"},
+		{Path: "comments.author.id", Type: "long", NestedPath: "comments", Indexed: true, DocValues: true},
+		{Path: "comments.text", Type: "text", NestedPath: "comments", Indexed: true, Analyzer: "standard"},
+		{Path: "payload", Type: "binary"},
+		{Path: "title", Type: "text", Indexed: true, Analyzer: "english", MultiFields: []string{"raw"}},
+		{Path: "title.raw", Type: "keyword", Indexed: true, DocValues: true},
+		{Path: "user.age", Type: "integer", Indexed: true, DocValues: true},
+		{Path: "user.name", Type: "keyword", Indexed: true},
+	}
+
+	if fields := FlattenMappings(mappings); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected fields\n%+v\ngot\n%+v", expected, fields)
+	}
+}
+
+func TestFieldTypeConflicts(t *testing.T) {
+	fields := map[string][]Field{
+		"logs-1": {{Path: "status", Type: "keyword"}, {Path: "took", Type: "long"}},
+		"logs-2": {{Path: "status", Type: "integer"}, {Path: "took", Type: "long"}},
+		"logs-3": {{Path: "status", Type: "keyword"}},
+	}
+
+	expected := map[string]map[string][]string{
+		"status": {
+			"keyword": {"logs-1", "logs-3"},
+			"integer": {"logs-2"},
+		},
+	}
+
+	if conflicts := FieldTypeConflicts(fields); !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("expected conflicts %v, got %v", expected, conflicts)
+	}
+}
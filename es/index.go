@@ -2,6 +2,7 @@ package es
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -67,13 +68,57 @@ func GetIndexDetails(index string, shouldGetMappings, shouldGetSettings bool) (I
 	return merged, nil
 }
 
+// GetMappings returns the mappings of the indices matching the given pattern keyed by index
+// name.
+func GetMappings(index string) (map[string]interface{}, error) {
+	if index == "" {
+		index = "_all"
+	}
+
+	var response MappingsResponse
+	if err := getJSONResponse(index+"/_mappings", &response); err != nil {
+		return nil, err
+	}
+
+	mappings := make(map[string]interface{}, len(response))
+	for name, indexMappings := range response {
+		mappings[name] = indexMappings.Mappings
+	}
+
+	return mappings, nil
+}
+
+type flatSettingsResponse map[string]struct {
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// GetFlatIndexSettings returns the settings of every index matching the given pattern,
+// keyed by their full dotted names.
+func GetFlatIndexSettings(index string) (map[string]map[string]interface{}, error) {
+	if index == "" {
+		index = "_all"
+	}
+
+	var response flatSettingsResponse
+	if err := getJSONResponse(index+"/_settings?flat_settings=true", &response); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]map[string]interface{}, len(response))
+	for name, indexSettings := range response {
+		settings[name] = indexSettings.Settings
+	}
+
+	return settings, nil
+}
+
 type AliasResponse map[string]AliasDetail
 
 type AliasDetail struct {
 	Aliases map[string]interface{} `json:"aliases"`
 }
 
-func GetAliases(index string) (map[string]string, error) {
+func getAliasResponse(index string) (AliasResponse, error) {
 	if index == "" {
 		index = "_all"
 	}
@@ -82,6 +127,14 @@ func GetAliases(index string) (map[string]string, error) {
 	if err := getJSONResponse(index+"/_alias", &aliasResp); err != nil {
 		return nil, err
 	}
+	return aliasResp, nil
+}
+
+func GetAliases(index string) (map[string]string, error) {
+	aliasResp, err := getAliasResponse(index)
+	if err != nil {
+		return nil, err
+	}
 
 	aliases := make(map[string]string)
 	for index, detail := range aliasResp {
@@ -93,12 +146,53 @@ func GetAliases(index string) (map[string]string, error) {
 	return aliases, nil
 }
 
+// GetAliasIndices returns the indices of every alias sorted by name. Unlike GetAliases it
+// keeps every index of an alias pointing to more than one index.
+func GetAliasIndices(index string) (map[string][]string, error) {
+	aliasResp, err := getAliasResponse(index)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string][]string)
+	for index, detail := range aliasResp {
+		for alias := range detail.Aliases {
+			aliases[alias] = append(aliases[alias], index)
+		}
+	}
+	for _, indices := range aliases {
+		sort.Strings(indices)
+	}
+
+	return aliases, nil
+}
+
 type CountResponse struct {
-	Count        int                    `json:"count"`
+	Count int `json:"count"`
+	Hits  struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+	} `json:"hits"`
 	Aggregations map[string]interface{} `json:"aggregations,omitempty"`
 }
 
-type GroupCount map[string]int
+// GroupBucket is the document count of one group, with a key for every grouping level
+// and the values of the metrics computed for the group.
+type GroupBucket struct {
+	Keys    []string
+	Count   int
+	Metrics map[string]float64
+}
+
+// GroupCount holds the groups of the documents of an index. Groups beyond the requested
+// size are reported as OtherGroupKey buckets and summed up in OtherDocCount, and
+// ErrorUpperBound is the largest error of the approximate terms counts.
+type GroupCount struct {
+	Buckets         []GroupBucket
+	OtherDocCount   int
+	ErrorUpperBound int
+}
 type IndexGroupCount map[string]GroupCount
 
 func buildFilterQueries(termFilters, existsFilters []string, nestedPaths []string) []map[string]interface{} {
@@ -194,27 +288,32 @@ func RefreshIndices(target string) error {
 	return postWithoutBody(endpoint, &response)
 }
 
+func buildCountQuery(termFilters, existsFilters, nestedPaths []string) map[string]interface{} {
+	if len(termFilters) > 0 || len(existsFilters) > 0 {
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": buildFilterQueries(termFilters, existsFilters, nestedPaths),
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"match_all": map[string]interface{}{},
+	}
+}
+
 func groupDocumentsOfIndex(
 	index string,
 	termFilters []string,
 	existsFilters []string,
 	nestedPaths []string,
-	groupBy string,
+	groupings []Grouping,
+	metrics []Metric,
 	size int,
 	timeout string,
+	missing string,
 ) (GroupCount, error) {
 	endpoint := index + "/_search"
-	query := map[string]interface{}{
-		"match_all": map[string]interface{}{},
-	}
-
-	if len(termFilters) > 0 || len(existsFilters) > 0 {
-		query = map[string]interface{}{
-			"bool": map[string]interface{}{
-				"must": buildFilterQueries(termFilters, existsFilters, nestedPaths),
-			},
-		}
-	}
 
 	if size <= 0 {
 		size = 50
@@ -224,65 +323,85 @@ func groupDocumentsOfIndex(
 		timeout = "1s"
 	}
 
-	nestedPath, isNestedPath := getNestedPath(groupBy, nestedPaths)
-	aggregations := make(map[string]interface{})
-
-	if isNestedPath {
-		aggregations["group_by_nested"] = map[string]interface{}{
-			"nested": map[string]interface{}{
-				"path": nestedPath,
-			},
-			"aggs": map[string]interface{}{
-				"group_by": map[string]interface{}{
-					"terms": map[string]interface{}{
-						"field": groupBy,
-						"size":  size,
-					},
-				},
-			},
-		}
-	} else {
-		aggregations["group_by"] = map[string]interface{}{
-			"terms": map[string]interface{}{
-				"field": groupBy,
-				"size":  size,
-			},
-		}
-	}
-
 	body := map[string]interface{}{
-		"query":   query,
-		"aggs":    aggregations,
+		"size":    0,
+		"query":   buildCountQuery(termFilters, existsFilters, nestedPaths),
 		"timeout": timeout,
 	}
 
+	if len(groupings) > 0 {
+		body["aggs"] = buildGroupAggregations(groupings, metrics, 0, "", nestedPaths, size, missing)
+	} else {
+		body["aggs"] = buildMetricAggregations(metrics, "", nestedPaths)
+		body["track_total_hits"] = true
+	}
+
 	var response CountResponse
 	if err := getJSONResponseWithBody(endpoint, &response, body); err != nil {
-		return nil, err
+		return GroupCount{}, err
 	}
 
-	groupCount := make(GroupCount)
-	var buckets map[string]interface{}
-
-	if isNestedPath {
-		if nestedAgg, ok := response.Aggregations["group_by_nested"].(map[string]interface{}); ok {
-			buckets, _ = nestedAgg["group_by"].(map[string]interface{})
-		}
+	groupCount := GroupCount{}
+	if len(groupings) > 0 {
+		collectGroupBuckets(response.Aggregations, groupings, metrics, 0, nil, &groupCount)
 	} else {
-		buckets, _ = response.Aggregations["group_by"].(map[string]interface{})
+		groupCount.Buckets = append(groupCount.Buckets, GroupBucket{
+			Count:   response.Hits.Total.Value,
+			Metrics: collectMetrics(response.Aggregations, metrics),
+		})
 	}
 
-	if terms, ok := buckets["buckets"].([]interface{}); ok {
-		for _, term := range terms {
-			if termData, ok := term.(map[string]interface{}); ok {
-				key := fmt.Sprint(termData["key"])
-				count := int(termData["doc_count"].(float64))
-				groupCount[key] = count
-			}
-		}
+	return groupCount, nil
+}
+
+// compositeGroupDocumentsOfIndex pages through every group with a composite aggregation,
+// so unlike groupDocumentsOfIndex the groups are complete and the counts are exact.
+func compositeGroupDocumentsOfIndex(
+	index string,
+	termFilters []string,
+	existsFilters []string,
+	nestedPaths []string,
+	groupings []Grouping,
+	metrics []Metric,
+	size int,
+	timeout string,
+	missing string,
+) (GroupCount, error) {
+	endpoint := index + "/_search"
+
+	if size <= 0 {
+		size = 1000
 	}
 
-	return groupCount, nil
+	if timeout == "" {
+		timeout = "1s"
+	}
+
+	groupCount := GroupCount{}
+	var after map[string]interface{}
+	for {
+		aggs, err := buildCompositeAggregation(groupings, metrics, nestedPaths, size, missing, after)
+		if err != nil {
+			return GroupCount{}, err
+		}
+
+		body := map[string]interface{}{
+			"size":    0,
+			"query":   buildCountQuery(termFilters, existsFilters, nestedPaths),
+			"aggs":    aggs,
+			"timeout": timeout,
+		}
+
+		var response CountResponse
+		if err := getJSONResponseWithBody(endpoint, &response, body); err != nil {
+			return GroupCount{}, err
+		}
+
+		after = collectCompositeBuckets(response.Aggregations, groupings, metrics, missing, &groupCount)
+		if after == nil {
+			return groupCount, nil
+		}
+	}
 }
 
 func CountDocuments(
@@ -290,9 +409,12 @@ func CountDocuments(
 	termFilters []string,
 	existsFilters []string,
 	nestedPaths []string,
-	groupBy string,
+	groupings []Grouping,
+	metrics []Metric,
 	size int,
 	timeout string,
+	missing string,
+	allBuckets bool,
 	refresh bool,
 ) (map[string]GroupCount, error) {
 	if refresh {
@@ -310,14 +432,19 @@ func CountDocuments(
 	indexCounts := make(map[string]GroupCount)
 	for _, index := range indices {
 		var groupCount GroupCount
-		if groupBy == "" {
+		if len(groupings) == 0 && len(metrics) == 0 {
 			count, err := countDocumentsOfIndex(index.Index, termFilters, existsFilters, nestedPaths)
 			if err != nil {
 				return nil, err
 			}
-			groupCount = map[string]int{"": count}
+			groupCount = GroupCount{Buckets: []GroupBucket{{Count: count}}}
+		} else if allBuckets && len(groupings) > 0 {
+			groupCount, err = compositeGroupDocumentsOfIndex(index.Index, termFilters, existsFilters, nestedPaths, groupings, metrics, size, timeout, missing)
+			if err != nil {
+				return nil, err
+			}
 		} else {
-			groupCount, err = groupDocumentsOfIndex(index.Index, termFilters, existsFilters, nestedPaths, groupBy, size, timeout)
+			groupCount, err = groupDocumentsOfIndex(index.Index, termFilters, existsFilters, nestedPaths, groupings, metrics, size, timeout, missing)
 			if err != nil {
 				return nil, err
 			}
@@ -15,15 +15,7 @@ func extractFieldAndValue(term string) (string, string, error) {
 	return parts[0], parts[1], nil
 }
 
-func SearchDocuments(
-	index string,
-	ids []string,
-	terms []string,
-	from int,
-	size int,
-	nestedPaths []string,
-	sortFields []string,
-) (JsonResponse, error) {
+func buildTermFilters(ids []string, terms []string, nestedPaths []string) ([]map[string]interface{}, error) {
 	var filters []map[string]interface{}
 
 	for _, term := range terms {
@@ -63,36 +55,136 @@ func SearchDocuments(
 		filters = append(filters, idsFilter)
 	}
 
-	query := map[string]interface{}{
-		"bool": map[string]interface{}{
+	return filters, nil
+}
+
+func buildSorts(sortFields []string) ([]interface{}, error) {
+	sorts := make([]interface{}, len(sortFields))
+	for i, sortField := range sortFields {
+		field, order, err := extractFieldAndValue(sortField)
+		if err != nil {
+			return nil, err
+		}
+		sorts[i] = map[string]string{field: order}
+	}
+	return sorts, nil
+}
+
+// mergeSearchBody merges the flag-built filters and sorts into a user provided search body.
+// The query of the body is kept as a must clause next to the filters, and the sorts are
+// appended after the sorts of the body.
+func mergeSearchBody(body JsonResponse, filters []map[string]interface{}, sorts []interface{}) JsonResponse {
+	if len(filters) > 0 {
+		boolQuery := map[string]interface{}{
 			"filter": filters,
-		},
+		}
+		if query, ok := body["query"]; ok && query != nil {
+			boolQuery["must"] = []interface{}{query}
+		}
+		body["query"] = map[string]interface{}{
+			"bool": boolQuery,
+		}
 	}
 
-	requestBody := map[string]interface{}{
-		"from":  from,
-		"size":  max(size, len(ids)),
-		"query": query,
+	if len(sorts) > 0 {
+		switch existing := body["sort"].(type) {
+		case nil:
+			body["sort"] = sorts
+		case []interface{}:
+			body["sort"] = append(existing, sorts...)
+		default:
+			body["sort"] = append([]interface{}{existing}, sorts...)
+		}
 	}
 
-	if len(sortFields) > 0 {
-		sorts := make([]map[string]string, len(sortFields))
-		for i, sortField := range sortFields {
-			field, order, err := extractFieldAndValue(sortField)
-			if err != nil {
-				return nil, err
-			}
-			sorts[i] = map[string]string{field: order}
+	return body
+}
+
+// SearchDocuments runs a search against the index. When body is nil the request body is built
+// entirely from the given filters, otherwise the filters, sorts, from and size are merged into
+// body. A negative from or size leaves the value of the body untouched.
+func SearchDocuments(
+	index string,
+	body JsonResponse,
+	ids []string,
+	terms []string,
+	from int,
+	size int,
+	nestedPaths []string,
+	sortFields []string,
+) (JsonResponse, error) {
+	filters, err := buildTermFilters(ids, terms, nestedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	sorts, err := buildSorts(sortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	var requestBody JsonResponse
+	if body == nil {
+		requestBody = JsonResponse{
+			"query": map[string]interface{}{
+				"bool": map[string]interface{}{
+					"filter": filters,
+				},
+			},
+		}
+		if len(sorts) > 0 {
+			requestBody["sort"] = sorts
 		}
-		requestBody["sort"] = sorts
+	} else {
+		requestBody = mergeSearchBody(body, filters, sorts)
+	}
+
+	if from >= 0 {
+		requestBody["from"] = from
+	}
+	if size >= 0 || len(ids) > 0 {
+		requestBody["size"] = max(size, len(ids))
 	}
 
 	endpoint := fmt.Sprintf("%s/_search", index)
 	var response JsonResponse
-	err := getJSONResponseWithBody(endpoint, &response, requestBody)
+	err = getJSONResponseWithBody(endpoint, &response, requestBody)
 	if err != nil {
 		return nil, err
 	}
 
 	return response, nil
 }
+
+// SetSourceFilter limits the returned _source to the given fields. Fields prefixed
+// with '-' are excluded instead.
+func SetSourceFilter(body JsonResponse, fields []string) {
+	var includes, excludes []string
+	for _, field := range fields {
+		if strings.HasPrefix(field, "-") {
+			excludes = append(excludes, strings.TrimPrefix(field, "-"))
+		} else {
+			includes = append(includes, field)
+		}
+	}
+
+	source := map[string]interface{}{}
+	if len(includes) > 0 {
+		source["includes"] = includes
+	}
+	if len(excludes) > 0 {
+		source["excludes"] = excludes
+	}
+	body["_source"] = source
+}
+
+// SetHighlight requests highlighted fragments for the given fields.
+func SetHighlight(body JsonResponse, fields []string) {
+	highlightFields := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		highlightFields[field] = map[string]interface{}{}
+	}
+	body["highlight"] = map[string]interface{}{
+		"fields": highlightFields,
+	}
+}
@@ -1,6 +1,9 @@
 package es
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestExtractFieldAndValue(t *testing.T) {
 	tests := []struct {
@@ -32,3 +35,47 @@ func TestExtractFieldAndValue(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeSearchBody(t *testing.T) {
+	matchQuery := map[string]interface{}{
+		"match": map[string]interface{}{"title": "elasticsearch"},
+	}
+	filters := []map[string]interface{}{
+		{"term": map[string]interface{}{"status": "published"}},
+	}
+	sorts := []interface{}{map[string]string{"price": "desc"}}
+
+	body := mergeSearchBody(JsonResponse{"query": matchQuery, "sort": "_score"}, filters, sorts)
+
+	boolQuery := body["query"].(map[string]interface{})["bool"].(map[string]interface{})
+	must := boolQuery["must"].([]interface{})
+	if len(must) != 1 || !reflect.DeepEqual(must[0], matchQuery) {
+		t.Errorf("expected the body query to be kept as must clause, got %v", boolQuery["must"])
+	}
+	if !reflect.DeepEqual(boolQuery["filter"], filters) {
+		t.Errorf("expected filters %v, got %v", filters, boolQuery["filter"])
+	}
+
+	sort := body["sort"].([]interface{})
+	if len(sort) != 2 || sort[0] != "_score" {
+		t.Errorf("expected flag sorts to be appended to the body sort, got %v", sort)
+	}
+
+	untouched := mergeSearchBody(JsonResponse{"query": matchQuery}, nil, nil)
+	if !reflect.DeepEqual(untouched["query"], matchQuery) {
+		t.Errorf("expected query to be untouched without filters, got %v", untouched["query"])
+	}
+}
+
+func TestSetSourceFilter(t *testing.T) {
+	body := JsonResponse{}
+	SetSourceFilter(body, []string{"title", "-author.email", "price"})
+
+	expected := map[string]interface{}{
+		"includes": []string{"title", "price"},
+		"excludes": []string{"author.email"},
+	}
+	if !reflect.DeepEqual(body["_source"], expected) {
+		t.Errorf("expected _source %v, got %v", expected, body["_source"])
+	}
+}
@@ -0,0 +1,189 @@
+package es
+
+import (
+	"fmt"
+	"time"
+)
+
+type IndexingStats struct {
+	IndexTotal        int64 `json:"index_total"`
+	IndexTimeInMillis int64 `json:"index_time_in_millis"`
+}
+
+type SearchStats struct {
+	QueryTotal        int64 `json:"query_total"`
+	QueryTimeInMillis int64 `json:"query_time_in_millis"`
+}
+
+type TimeStats struct {
+	Total             int64 `json:"total"`
+	TotalTimeInMillis int64 `json:"total_time_in_millis"`
+}
+
+// OperationStats holds the cumulative operation counters of an index or a node.
+type OperationStats struct {
+	Indexing IndexingStats `json:"indexing"`
+	Search   SearchStats   `json:"search"`
+	Merges   TimeStats     `json:"merges"`
+	Refresh  TimeStats     `json:"refresh"`
+}
+
+type indexStatsResponse struct {
+	Indices map[string]struct {
+		Total OperationStats `json:"total"`
+	} `json:"indices"`
+}
+
+// GetIndexStats returns the operation counters of every index matching the given pattern,
+// summed over primaries and replicas.
+func GetIndexStats(index string) (map[string]OperationStats, error) {
+	endpoint := "_stats/indexing,search,merge,refresh"
+	if index != "" {
+		endpoint = fmt.Sprintf("%s/%s", index, endpoint)
+	}
+
+	var response indexStatsResponse
+	if err := getJSONResponse(endpoint, &response); err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]OperationStats, len(response.Indices))
+	for name, indexStats := range response.Indices {
+		stats[name] = indexStats.Total
+	}
+
+	return stats, nil
+}
+
+type nodeStatsResponse struct {
+	Nodes map[string]struct {
+		Name    string         `json:"name"`
+		Indices OperationStats `json:"indices"`
+	} `json:"nodes"`
+}
+
+// GetNodeStats returns the operation counters of every node by node name.
+func GetNodeStats() (map[string]OperationStats, error) {
+	endpoint := "_nodes/stats/indices/indexing,search,merge,refresh"
+
+	var response nodeStatsResponse
+	if err := getJSONResponse(endpoint, &response); err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]OperationStats, len(response.Nodes))
+	for _, nodeStats := range response.Nodes {
+		stats[nodeStats.Name] = nodeStats.Indices
+	}
+
+	return stats, nil
+}
+
+// OperationRates are the per second rates of the operation counters between two samples.
+type OperationRates struct {
+	IndexingRate  float64
+	SearchRate    float64
+	QueryLatency  float64
+	MergeTimeRate float64
+	RefreshRate   float64
+}
+
+// delta returns the increase of a counter, treating a counter that went backwards, e.g.
+// after a node restart, as no increase.
+func delta(previous, current int64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current - previous)
+}
+
+// ComputeRates returns the rates of the counters between two samples taken elapsed apart.
+// QueryLatency is the average time of the queries run in between, in milliseconds.
+func ComputeRates(previous, current OperationStats, elapsed time.Duration) OperationRates {
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return OperationRates{}
+	}
+
+	rates := OperationRates{
+		IndexingRate:  delta(previous.Indexing.IndexTotal, current.Indexing.IndexTotal) / seconds,
+		SearchRate:    delta(previous.Search.QueryTotal, current.Search.QueryTotal) / seconds,
+		MergeTimeRate: delta(previous.Merges.TotalTimeInMillis, current.Merges.TotalTimeInMillis) / seconds,
+		RefreshRate:   delta(previous.Refresh.TotalTimeInMillis, current.Refresh.TotalTimeInMillis) / seconds,
+	}
+
+	if queries := delta(previous.Search.QueryTotal, current.Search.QueryTotal); queries > 0 {
+		rates.QueryLatency = delta(previous.Search.QueryTimeInMillis, current.Search.QueryTimeInMillis) / queries
+	}
+
+	return rates
+}
+
+// SampleRates takes two samples of the counters interval apart and returns the rates of
+// every key present in both samples.
+func SampleRates(sample func() (map[string]OperationStats, error), interval time.Duration) (map[string]OperationRates, error) {
+	previous, err := sample()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	time.Sleep(interval)
+
+	current, err := sample()
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+
+	rates := make(map[string]OperationRates, len(current))
+	for key, stats := range current {
+		if previousStats, ok := previous[key]; ok {
+			rates[key] = ComputeRates(previousStats, stats, elapsed)
+		}
+	}
+
+	return rates, nil
+}
+
+type ThreadPool struct {
+	NodeName string `json:"node_name"`
+	Name     string `json:"name"`
+	Active   string `json:"active"`
+	Queue    string `json:"queue"`
+	Rejected string `json:"rejected"`
+}
+
+func GetThreadPools() ([]ThreadPool, error) {
+	endpoint := "_cat/thread_pool?format=json&h=node_name,name,active,queue,rejected"
+
+	var threadPools []ThreadPool
+	if err := getJSONResponse(endpoint, &threadPools); err != nil {
+		return nil, err
+	}
+
+	return threadPools, nil
+}
+
+type Recovery struct {
+	Index        string `json:"index"`
+	Shard        string `json:"shard"`
+	Type         string `json:"type"`
+	Stage        string `json:"stage"`
+	SourceNode   string `json:"source_node"`
+	TargetNode   string `json:"target_node"`
+	BytesPercent string `json:"bytes_percent"`
+	Time         string `json:"time"`
+}
+
+// GetActiveRecoveries returns the shard recoveries that are still in progress.
+func GetActiveRecoveries() ([]Recovery, error) {
+	endpoint := "_cat/recovery?active_only=true&format=json&h=index,shard,type,stage,source_node,target_node,bytes_percent,time"
+
+	var recoveries []Recovery
+	if err := getJSONResponse(endpoint, &recoveries); err != nil {
+		return nil, err
+	}
+
+	return recoveries, nil
+}
@@ -0,0 +1,60 @@
+package es
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeRates(t *testing.T) {
+	previous := OperationStats{
+		Indexing: IndexingStats{IndexTotal: 1000},
+		Search:   SearchStats{QueryTotal: 200, QueryTimeInMillis: 4000},
+		Merges:   TimeStats{TotalTimeInMillis: 500},
+		Refresh:  TimeStats{TotalTimeInMillis: 100},
+	}
+	current := OperationStats{
+		Indexing: IndexingStats{IndexTotal: 1500},
+		Search:   SearchStats{QueryTotal: 300, QueryTimeInMillis: 5500},
+		Merges:   TimeStats{TotalTimeInMillis: 2500},
+		Refresh:  TimeStats{TotalTimeInMillis: 300},
+	}
+
+	tests := []struct {
+		name     string
+		previous OperationStats
+		current  OperationStats
+		elapsed  time.Duration
+		expected OperationRates
+	}{
+		{
+			name:     "increasing counters",
+			previous: previous,
+			current:  current,
+			elapsed:  5 * time.Second,
+			expected: OperationRates{IndexingRate: 100, SearchRate: 20, QueryLatency: 15, MergeTimeRate: 400, RefreshRate: 40},
+		},
+		{
+			name:     "reset counters",
+			previous: current,
+			current:  previous,
+			elapsed:  5 * time.Second,
+			expected: OperationRates{},
+		},
+		{
+			name:     "no elapsed time",
+			previous: previous,
+			current:  current,
+			elapsed:  0,
+			expected: OperationRates{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rates := ComputeRates(test.previous, test.current, test.elapsed)
+			if rates != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, rates)
+			}
+		})
+	}
+}
@@ -54,3 +54,19 @@ func GetTasks(actions []string) (TasksResponse, error) {
 
 	return response, nil
 }
+
+type TaskStatus struct {
+	Completed bool                   `json:"completed"`
+	Task      Task                   `json:"task"`
+	Error     map[string]interface{} `json:"error,omitempty"`
+	Response  map[string]interface{} `json:"response,omitempty"`
+}
+
+// GetTask returns the status of a single task identified as NODE:ID.
+func GetTask(taskID string) (TaskStatus, error) {
+	var status TaskStatus
+	if err := getJSONResponse("_tasks/"+url.PathEscape(taskID), &status); err != nil {
+		return TaskStatus{}, err
+	}
+	return status, nil
+}
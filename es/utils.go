@@ -21,13 +21,52 @@ type EsError struct {
 	Status int `json:"status"`
 }
 
+var client = http.DefaultClient
+
+// SetTransport replaces the transport of the requests to Elasticsearch, e.g. to serve
+// recorded responses instead of calling a live cluster.
+func SetTransport(transport http.RoundTripper) {
+	client = &http.Client{Transport: transport}
+}
+
 func debugLog(format string, args ...interface{}) {
 	if shared.Debug {
 		fmt.Fprintf(os.Stderr, "DEBUG: "+format+"\n", args...)
 	}
 }
 
-func httpRequest(method, endpoint string, body, target interface{}, expectedStatusCode int) error {
+func httpRequest(method, endpoint string, body, target interface{}, expectedStatusCodes ...int) error {
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rawHttpRequest(method, endpoint, "application/json", bodyBytes, target, expectedStatusCodes...)
+}
+
+func rawHttpRequest(method, endpoint, contentType string, body []byte, target interface{}, expectedStatusCodes ...int) error {
+	resp, err := doRequest(method, endpoint, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !isExpectedStatus(resp.StatusCode, expectedStatusCodes) {
+		var esError EsError
+		if err := json.NewDecoder(resp.Body).Decode(&esError); err != nil {
+			return fmt.Errorf("unexpected http status: %s", resp.Status)
+		}
+		return errors.New(esError.Error.Reason)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func doRequest(method, endpoint, contentType string, body []byte) (*http.Response, error) {
 	baseURL := fmt.Sprintf("%s://%s:%d/%s", shared.ElasticsearchProtocol, shared.ElasticsearchHost, shared.ElasticsearchPort, endpoint)
 
 	if shared.Debug {
@@ -36,42 +75,49 @@ func httpRequest(method, endpoint string, body, target interface{}, expectedStat
 
 	var bodyReader io.Reader
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
-		if err != nil {
-			return err
-		}
 		if shared.Debug {
-			debugLog("Request Body: %s", bodyBytes)
+			debugLog("Request Body: %s", body)
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
+		bodyReader = bytes.NewReader(body)
 	}
 
 	req, err := http.NewRequest(method, baseURL, bodyReader)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if shared.ElasticsearchUsername != "" && shared.ElasticsearchPassword != "" {
 		req.SetBasicAuth(shared.ElasticsearchUsername, shared.ElasticsearchPassword)
 	}
 
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", contentType)
 
-	resp, err := http.DefaultClient.Do(req)
+	return client.Do(req)
+}
+
+// GetRaw returns the body and the status code of a GET request as is, whatever the status.
+func GetRaw(endpoint string) ([]byte, int, error) {
+	resp, err := doRequest(http.MethodGet, endpoint, "application/json", nil)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != expectedStatusCode {
-		var esError EsError
-		if err := json.NewDecoder(resp.Body).Decode(&esError); err != nil {
-			return fmt.Errorf("unexpected http status: %s", resp.Status)
-		}
-		return errors.New(esError.Error.Reason)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
 	}
 
-	return json.NewDecoder(resp.Body).Decode(target)
+	return body, resp.StatusCode, nil
+}
+
+func isExpectedStatus(statusCode int, expectedStatusCodes []int) bool {
+	for _, expected := range expectedStatusCodes {
+		if statusCode == expected {
+			return true
+		}
+	}
+	return false
 }
 
 func getJSONResponse(endpoint string, target interface{}) error {
@@ -0,0 +1,234 @@
+package forecast
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fehmicansaglam/esctl/output"
+)
+
+// Watermark is a disk watermark and its default value.
+type Watermark struct {
+	Name    string
+	Default string
+}
+
+// Watermarks are the disk watermarks from the lowest to the highest.
+var Watermarks = []Watermark{
+	{Name: "low", Default: "85%"},
+	{Name: "high", Default: "90%"},
+	{Name: "flood_stage", Default: "95%"},
+}
+
+// Threshold returns the used bytes at which a disk of the given total size reaches the
+// watermark, given either as a percentage or ratio of used disk or as an amount of free disk.
+func Threshold(watermark string, total float64) (float64, error) {
+	if strings.HasSuffix(watermark, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(watermark, "%"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid watermark %q: %w", watermark, err)
+		}
+		return total * percent / 100, nil
+	}
+
+	if ratio, err := strconv.ParseFloat(watermark, 64); err == nil {
+		return total * ratio, nil
+	}
+
+	free, err := output.ParseDataSize(watermark)
+	if err != nil {
+		return 0, fmt.Errorf("invalid watermark %q: %w", watermark, err)
+	}
+	return total - free, nil
+}
+
+// Point is a value observed at a time.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Trend is the linear growth of a series of points.
+type Trend struct {
+	// PerDay is the growth per day.
+	PerDay float64
+}
+
+// Fit returns the least squares trend of the points. It needs at least two points at
+// different times.
+func Fit(points []Point) (Trend, bool) {
+	if len(points) < 2 {
+		return Trend{}, false
+	}
+
+	origin := points[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, point := range points {
+		x := point.Time.Sub(origin).Hours() / 24
+		sumX += x
+		sumY += point.Value
+		sumXY += x * point.Value
+		sumXX += x * x
+	}
+
+	n := float64(len(points))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return Trend{}, false
+	}
+	return Trend{PerDay: (n*sumXY - sumX*sumY) / denominator}, true
+}
+
+// MaxETADays is the furthest ETA estimated. Further ETAs are reported as Beyond, which
+// also keeps them from overflowing a time.Duration at about 292 years.
+const MaxETADays = 100 * 365
+
+// ETA is the estimated time until a threshold is reached.
+type ETA struct {
+	Reached bool
+	// Never is set when the value is not growing.
+	Never bool
+	// Beyond is set when the threshold is more than MaxETADays away.
+	Beyond bool
+	In     time.Duration
+}
+
+// Until returns the time until the value grows from current to threshold.
+func (t Trend) Until(current, threshold float64) ETA {
+	switch {
+	case current >= threshold:
+		return ETA{Reached: true}
+	case t.PerDay <= 0:
+		return ETA{Never: true}
+	}
+	days := (threshold - current) / t.PerDay
+	if days > MaxETADays {
+		return ETA{Beyond: true}
+	}
+	return ETA{In: time.Duration(days * 24 * float64(time.Hour))}
+}
+
+// Forecast is the disk usage of a node or of the cluster and the estimated time until each
+// watermark is reached.
+type Forecast struct {
+	Name  string
+	Used  float64
+	Total float64
+	// Fitted is false when there are not enough samples to fit a trend, in which case
+	// the ETAs only tell whether the watermarks are reached.
+	Fitted bool
+	Trend  Trend
+	// ETAs are keyed by watermark name.
+	ETAs map[string]ETA
+}
+
+// Disk returns the forecasts of the nodes in the latest sample and of the cluster as a
+// whole. The watermarks are keyed by name; missing ones take their default values. The
+// cluster grows by the sum of the trends of its nodes, so that nodes joining or leaving
+// between the samples are not mistaken for growth or shrinkage.
+func Disk(samples []Sample, watermarks map[string]string) ([]Forecast, Forecast, error) {
+	if len(samples) == 0 {
+		return nil, Forecast{}, nil
+	}
+	latest := samples[len(samples)-1]
+
+	names := make([]string, 0, len(latest.Nodes))
+	for name := range latest.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cluster := Forecast{Name: "cluster", ETAs: make(map[string]ETA)}
+	clusterThresholds := make(map[string]float64)
+
+	nodes := make([]Forecast, 0, len(names))
+	for _, name := range names {
+		disk := latest.Nodes[name]
+		forecast := Forecast{Name: name, Used: disk.Used, Total: disk.Total, ETAs: make(map[string]ETA)}
+		forecast.Trend, forecast.Fitted = Fit(nodeSeries(samples, name))
+
+		for _, watermark := range Watermarks {
+			value, ok := watermarks[watermark.Name]
+			if !ok {
+				value = watermark.Default
+			}
+			threshold, err := Threshold(value, disk.Total)
+			if err != nil {
+				return nil, Forecast{}, err
+			}
+			clusterThresholds[watermark.Name] += threshold
+			forecast.ETAs[watermark.Name] = forecast.Trend.Until(disk.Used, threshold)
+		}
+
+		cluster.Used += disk.Used
+		cluster.Total += disk.Total
+		if forecast.Fitted {
+			cluster.Trend.PerDay += forecast.Trend.PerDay
+			cluster.Fitted = true
+		}
+		nodes = append(nodes, forecast)
+	}
+
+	for name, threshold := range clusterThresholds {
+		cluster.ETAs[name] = cluster.Trend.Until(cluster.Used, threshold)
+	}
+
+	return nodes, cluster, nil
+}
+
+// IndexGrowth is the store size of an index and its growth per day.
+type IndexGrowth struct {
+	Index string
+	Store float64
+	Trend Trend
+}
+
+// FastestGrowingIndices returns the n indices of the latest sample with the highest growth
+// per day.
+func FastestGrowingIndices(samples []Sample, n int) []IndexGrowth {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var growths []IndexGrowth
+	for index, store := range samples[len(samples)-1].Indices {
+		if trend, ok := Fit(indexSeries(samples, index)); ok && trend.PerDay > 0 {
+			growths = append(growths, IndexGrowth{Index: index, Store: store, Trend: trend})
+		}
+	}
+
+	sort.Slice(growths, func(i, j int) bool {
+		if growths[i].Trend.PerDay != growths[j].Trend.PerDay {
+			return growths[i].Trend.PerDay > growths[j].Trend.PerDay
+		}
+		return growths[i].Index < growths[j].Index
+	})
+
+	if len(growths) > n {
+		growths = growths[:n]
+	}
+	return growths
+}
+
+func nodeSeries(samples []Sample, node string) []Point {
+	var points []Point
+	for _, sample := range samples {
+		if disk, ok := sample.Nodes[node]; ok {
+			points = append(points, Point{Time: sample.Time, Value: disk.Used})
+		}
+	}
+	return points
+}
+
+func indexSeries(samples []Sample, index string) []Point {
+	var points []Point
+	for _, sample := range samples {
+		if store, ok := sample.Indices[index]; ok {
+			points = append(points, Point{Time: sample.Time, Value: store})
+		}
+	}
+	return points
+}
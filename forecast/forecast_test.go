@@ -0,0 +1,145 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+)
+
+const gb = 1024 * 1024 * 1024
+
+func TestThreshold(t *testing.T) {
+	tests := []struct {
+		watermark string
+		total     float64
+		expected  float64
+	}{
+		{"85%", 100, 85},
+		{"0.9", 100, 90},
+		{"10gb", 100 * gb, 90 * gb},
+	}
+
+	for _, test := range tests {
+		actual, err := Threshold(test.watermark, test.total)
+		if err != nil {
+			t.Fatalf("Threshold(%q): %v", test.watermark, err)
+		}
+		if actual != test.expected {
+			t.Errorf("Threshold(%q): expected %g, got %g", test.watermark, test.expected, actual)
+		}
+	}
+
+	if _, err := Threshold("lots", 100); err == nil {
+		t.Error("expected an error for an invalid watermark")
+	}
+}
+
+func TestFit(t *testing.T) {
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	points := []Point{
+		{Time: start, Value: 10},
+		{Time: start.Add(24 * time.Hour), Value: 12},
+		{Time: start.Add(48 * time.Hour), Value: 14},
+	}
+
+	trend, ok := Fit(points)
+	if !ok || trend.PerDay != 2 {
+		t.Errorf("expected a growth of 2 per day, got %+v (%v)", trend, ok)
+	}
+
+	if _, ok := Fit(points[:1]); ok {
+		t.Error("expected no trend from a single point")
+	}
+	if _, ok := Fit([]Point{points[0], points[0]}); ok {
+		t.Error("expected no trend from points at the same time")
+	}
+}
+
+func TestUntil(t *testing.T) {
+	tests := []struct {
+		name     string
+		trend    Trend
+		current  float64
+		expected ETA
+	}{
+		{"growing", Trend{PerDay: 5}, 80, ETA{In: 2 * 24 * time.Hour}},
+		{"reached", Trend{PerDay: 5}, 95, ETA{Reached: true}},
+		{"shrinking", Trend{PerDay: -1}, 80, ETA{Never: true}},
+		{"centuries away", Trend{PerDay: 1e-6}, 80, ETA{Beyond: true}},
+		{"at the limit", Trend{PerDay: 10.0 / MaxETADays}, 80, ETA{In: MaxETADays * 24 * time.Hour}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.trend.Until(test.current, 90); actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDisk(t *testing.T) {
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: start, Nodes: map[string]NodeDisk{"a": {Used: 70, Total: 100}, "b": {Used: 10, Total: 100}}},
+		{Time: start.Add(24 * time.Hour), Nodes: map[string]NodeDisk{"a": {Used: 80, Total: 100}, "b": {Used: 10, Total: 100}}},
+	}
+
+	nodes, cluster, err := Disk(samples, map[string]string{"flood_stage": "0.9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 2 || nodes[0].Name != "a" || nodes[1].Name != "b" {
+		t.Fatalf("unexpected nodes: %+v", nodes)
+	}
+
+	expected := map[string]ETA{
+		"low":         {In: 12 * time.Hour},
+		"high":        {In: 24 * time.Hour},
+		"flood_stage": {In: 24 * time.Hour},
+	}
+	for name, eta := range expected {
+		if nodes[0].ETAs[name] != eta {
+			t.Errorf("node a, %s: expected %+v, got %+v", name, eta, nodes[0].ETAs[name])
+		}
+	}
+	if !nodes[1].ETAs["low"].Never {
+		t.Errorf("expected node b to never reach the low watermark, got %+v", nodes[1].ETAs["low"])
+	}
+
+	// The cluster grows 10 per day from 90 towards 170 used at the low watermark.
+	if cluster.Used != 90 || cluster.Total != 200 || cluster.ETAs["low"] != (ETA{In: 8 * 24 * time.Hour}) {
+		t.Errorf("unexpected cluster forecast: %+v", cluster)
+	}
+
+	// Node c joins with 50 used and node b leaves, which adds 40 to the used disk of the
+	// cluster without any node growing faster than 10 per day.
+	samples = append(samples, Sample{
+		Time:  start.Add(48 * time.Hour),
+		Nodes: map[string]NodeDisk{"a": {Used: 90, Total: 100}, "c": {Used: 50, Total: 100}},
+	})
+
+	_, cluster, err = Disk(samples, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cluster.Fitted || cluster.Trend.PerDay != 10 {
+		t.Errorf("expected the cluster to grow 10 per day, got %+v", cluster)
+	}
+}
+
+func TestFastestGrowingIndices(t *testing.T) {
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: start, Indices: map[string]float64{"logs": 10, "metrics": 10, "static": 10}},
+		{Time: start.Add(24 * time.Hour), Indices: map[string]float64{"logs": 30, "metrics": 15, "static": 10}},
+	}
+
+	growths := FastestGrowingIndices(samples, 5)
+	if len(growths) != 2 || growths[0].Index != "logs" || growths[1].Index != "metrics" {
+		t.Fatalf("unexpected growths: %+v", growths)
+	}
+	if growths[0].Store != 30 || growths[0].Trend.PerDay != 20 {
+		t.Errorf("unexpected growth of logs: %+v", growths[0])
+	}
+}
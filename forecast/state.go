@@ -0,0 +1,80 @@
+package forecast
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// NodeDisk is the disk usage of a node in bytes.
+type NodeDisk struct {
+	Used  float64 `json:"used"`
+	Total float64 `json:"total"`
+}
+
+// Sample is the disk usage of the nodes and the store size of the indices at a time.
+type Sample struct {
+	Time    time.Time           `json:"time"`
+	Nodes   map[string]NodeDisk `json:"nodes"`
+	Indices map[string]float64  `json:"indices"`
+}
+
+// State holds the samples of every cluster, keyed by the cluster address.
+type State struct {
+	Clusters map[string][]Sample `json:"clusters"`
+}
+
+// Load reads the state file. A missing file is an empty state.
+func Load(path string) (*State, error) {
+	state := &State{Clusters: make(map[string][]Sample)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Clusters == nil {
+		state.Clusters = make(map[string][]Sample)
+	}
+	return state, nil
+}
+
+// Save writes the state file, creating its directory when needed.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Record adds the sample to the samples of the cluster and drops the samples older than
+// the retention.
+func (s *State) Record(cluster string, sample Sample, retention time.Duration) {
+	samples := append(s.Clusters[cluster], sample)
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Time.Before(samples[j].Time)
+	})
+
+	cutoff := sample.Time.Add(-retention)
+	kept := samples[:0]
+	for _, existing := range samples {
+		if retention <= 0 || !existing.Time.Before(cutoff) {
+			kept = append(kept, existing)
+		}
+	}
+	s.Clusters[cluster] = kept
+}
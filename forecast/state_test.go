@@ -0,0 +1,55 @@
+package forecast
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "forecast.json")
+
+	state, err := Load(path)
+	if err != nil {
+		t.Fatalf("loading a missing state: %v", err)
+	}
+	if len(state.Clusters) != 0 {
+		t.Fatalf("expected an empty state, got %+v", state)
+	}
+
+	sample := Sample{
+		Time:    time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		Nodes:   map[string]NodeDisk{"a": {Used: 1, Total: 2}},
+		Indices: map[string]float64{"logs": 3},
+	}
+	state.Record("http://localhost:9200", sample, 0)
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := loaded.Clusters["http://localhost:9200"]
+	if len(samples) != 1 || !samples[0].Time.Equal(sample.Time) || samples[0].Nodes["a"] != sample.Nodes["a"] || samples[0].Indices["logs"] != 3 {
+		t.Errorf("unexpected samples after loading: %+v", samples)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	state := &State{Clusters: make(map[string][]Sample)}
+
+	state.Record("c", Sample{Time: start.Add(48 * time.Hour)}, 0)
+	state.Record("c", Sample{Time: start}, 0)
+	state.Record("c", Sample{Time: start.Add(72 * time.Hour)}, 48*time.Hour)
+
+	samples := state.Clusters["c"]
+	if len(samples) != 2 {
+		t.Fatalf("expected the oldest sample to be dropped, got %d samples", len(samples))
+	}
+	if !samples[0].Time.Equal(start.Add(48*time.Hour)) || !samples[1].Time.Equal(start.Add(72*time.Hour)) {
+		t.Errorf("expected the samples in time order, got %+v", samples)
+	}
+}
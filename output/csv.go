@@ -0,0 +1,23 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+)
+
+func PrintCsv(columnDefs []ColumnDef, data [][]string) {
+	w := csv.NewWriter(os.Stdout)
+
+	headers := make([]string, len(columnDefs))
+	for i, columnDef := range columnDefs {
+		headers[i] = columnDef.Header
+	}
+	w.Write(headers)
+	w.WriteAll(data)
+
+	if err := w.Error(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to write CSV:", err)
+		os.Exit(1)
+	}
+}
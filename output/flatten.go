@@ -0,0 +1,62 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten converts a nested JSON object into a map of dot-separated paths to display values.
+// Arrays are flattened element by element and their values are joined with commas.
+func Flatten(object map[string]interface{}) map[string]string {
+	values := make(map[string][]string)
+	flattenValue(object, "", values)
+
+	flattened := make(map[string]string, len(values))
+	for path, vals := range values {
+		flattened[path] = strings.Join(vals, ",")
+	}
+	return flattened
+}
+
+func flattenValue(value interface{}, path string, values map[string][]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(child, childPath, values)
+		}
+	case []interface{}:
+		for _, element := range v {
+			flattenValue(element, path, values)
+		}
+	case nil:
+		values[path] = append(values[path], "")
+	case string:
+		values[path] = append(values[path], v)
+	case float64:
+		values[path] = append(values[path], strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		values[path] = append(values[path], fmt.Sprint(v))
+	}
+}
+
+// FlattenedKeys returns the sorted union of the keys of the flattened objects.
+func FlattenedKeys(objects []map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, object := range objects {
+		for key := range object {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	object := map[string]interface{}{
+		"title": "esctl",
+		"price": 10.5,
+		"draft": false,
+		"author": map[string]interface{}{
+			"name": "jane",
+			"tags": []interface{}{"a", "b"},
+		},
+		"comments": []interface{}{
+			map[string]interface{}{"user": "u1"},
+			map[string]interface{}{"user": "u2"},
+		},
+		"deleted": nil,
+	}
+
+	expected := map[string]string{
+		"title":         "esctl",
+		"price":         "10.5",
+		"draft":         "false",
+		"author.name":   "jane",
+		"author.tags":   "a,b",
+		"comments.user": "u1,u2",
+		"deleted":       "",
+	}
+
+	if result := Flatten(object); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Flatten() = %v, want %v", result, expected)
+	}
+}
+
+func TestFlattenedKeys(t *testing.T) {
+	objects := []map[string]string{
+		{"b": "1", "a": "2"},
+		{"c": "3", "a": "4"},
+	}
+
+	expected := []string{"a", "b", "c"}
+	if result := FlattenedKeys(objects); !reflect.DeepEqual(result, expected) {
+		t.Errorf("FlattenedKeys() = %v, want %v", result, expected)
+	}
+}
@@ -5,12 +5,21 @@ import (
 	"fmt"
 )
 
-func PrintJson(data interface{}) {
+func FormatJson(data interface{}) (string, error) {
 	prettyJSON, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(prettyJSON), nil
+}
+
+func PrintJson(data interface{}) {
+	prettyJSON, err := FormatJson(data)
 	if err != nil {
 		fmt.Println("Failed to generate pretty JSON:", err)
 		return
 	}
 
-	fmt.Println(string(prettyJSON))
+	fmt.Println(prettyJSON)
 }
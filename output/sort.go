@@ -2,6 +2,7 @@ package output
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -51,8 +52,8 @@ func sortNumber(left, right string) bool {
 }
 
 func sortDataSize(left, right string) bool {
-	size1, _ := parseDataSize(left)
-	size2, _ := parseDataSize(right)
+	size1, _ := ParseDataSize(left)
+	size2, _ := ParseDataSize(right)
 	return size1 < size2
 }
 
@@ -76,7 +77,8 @@ func sortDate(val1, val2 string) bool {
 	return time1.Before(time2)
 }
 
-func parseDataSize(sizeStr string) (float64, error) {
+// ParseDataSize converts a size as printed by the cat APIs, e.g. 1.5gb, to bytes.
+func ParseDataSize(sizeStr string) (float64, error) {
 	if sizeStr == "" {
 		return 0, nil
 	}
@@ -90,7 +92,6 @@ func parseDataSize(sizeStr string) (float64, error) {
 		if (sizeStr[i] < '0' || sizeStr[i] > '9') && sizeStr[i] != '.' {
 			value, err = strconv.ParseFloat(sizeStr[:i], 64)
 			if err != nil {
-				fmt.Println(err)
 				return 0, err
 			}
 			unit = sizeStr[i:]
@@ -109,8 +110,23 @@ func parseDataSize(sizeStr string) (float64, error) {
 		return value * 1024 * 1024 * 1024, nil
 	case "tb":
 		return value * 1024 * 1024 * 1024 * 1024, nil
+	case "pb":
+		return value * 1024 * 1024 * 1024 * 1024 * 1024, nil
 	default:
-		fmt.Printf("unknown unit: %s\n", unit)
 		return 0, fmt.Errorf("unknown unit: %s", unit)
 	}
 }
+
+var dataSizeUnits = []string{"b", "kb", "mb", "gb", "tb", "pb"}
+
+// FormatDataSize formats bytes the way the cat APIs print sizes, e.g. 1.5gb.
+func FormatDataSize(bytes int64) string {
+	value := float64(bytes)
+	unit := 0
+	// Compare the rounded value so that e.g. 1023.99mb is printed as 1gb.
+	for math.Round(value*10)/10 >= 1024 && unit < len(dataSizeUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64) + dataSizeUnits[unit]
+}
@@ -16,6 +16,7 @@ func TestParseDataSize(t *testing.T) {
 		{"Megabytes", "10mb", 10 * 1024 * 1024},
 		{"Gigabytes", "10gb", 10 * 1024 * 1024 * 1024},
 		{"Terabytes", "10tb", 10 * 1024 * 1024 * 1024 * 1024},
+		{"Petabytes", "10pb", 10 * 1024 * 1024 * 1024 * 1024 * 1024},
 		{"Fractional kilobytes", "5.6kb", 5.6 * 1024},
 		{"Invalid unit", "10ab", 0},
 		{"Invalid value", "ab10", 0},
@@ -24,12 +25,36 @@ func TestParseDataSize(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := parseDataSize(tc.input)
+			result, err := ParseDataSize(tc.input)
 			if err != nil && tc.expected != 0 {
 				t.Errorf("unexpected error: %v", err)
 			}
 			if result != tc.expected {
-				t.Errorf("parseDataSize(%s) = %f, want %f", tc.input, result, tc.expected)
+				t.Errorf("ParseDataSize(%s) = %f, want %f", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFormatDataSize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    int64
+		expected string
+	}{
+		{"Zero", 0, "0b"},
+		{"Bytes", 1023, "1023b"},
+		{"Kilobytes", 1024, "1kb"},
+		{"Fractional megabytes", 1536 * 1024, "1.5mb"},
+		{"Rounded gigabytes", 49*1024*1024*1024 + 300*1024*1024, "49.3gb"},
+		{"Terabytes", 2 * 1024 * 1024 * 1024 * 1024, "2tb"},
+		{"Rounded up to the next unit", 1024*1024*1024 - 1, "1gb"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := FormatDataSize(tc.input); result != tc.expected {
+				t.Errorf("FormatDataSize(%d) = %s, want %s", tc.input, result, tc.expected)
 			}
 		})
 	}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -40,6 +41,11 @@ func compareValues(left, right string, columnType ColumnType) bool {
 }
 
 func PrintTable(columnDefs []ColumnDef, data [][]string, sortByHeaders ...string) {
+	RenderTable(os.Stdout, columnDefs, data, sortByHeaders...)
+}
+
+// RenderTable writes the table to w. The rows of data are sorted in place.
+func RenderTable(w io.Writer, columnDefs []ColumnDef, data [][]string, sortByHeaders ...string) {
 	// Determine if a column is empty
 	emptyColumns := make([]bool, len(columnDefs))
 	for i := range columnDefs {
@@ -83,24 +89,24 @@ func PrintTable(columnDefs []ColumnDef, data [][]string, sortByHeaders ...string
 		})
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	defer w.Flush()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
 
 	// Write headers
 	for i, columnDef := range columnDefs {
 		if !emptyColumns[i] {
-			fmt.Fprintf(w, "%s\t", columnDef.Header)
+			fmt.Fprintf(tw, "%s\t", columnDef.Header)
 		}
 	}
-	fmt.Fprintln(w)
+	fmt.Fprintln(tw)
 
 	// Write data
 	for _, row := range data {
 		for i, cell := range row {
 			if !emptyColumns[i] {
-				fmt.Fprintf(w, "%s\t", cell)
+				fmt.Fprintf(tw, "%s\t", cell)
 			}
 		}
-		fmt.Fprintln(w)
+		fmt.Fprintln(tw)
 	}
 }
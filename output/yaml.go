@@ -7,12 +7,21 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-func PrintYaml(data interface{}) {
+func FormatYaml(data interface{}) (string, error) {
 	yamlData, err := yaml.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(yamlData), nil
+}
+
+func PrintYaml(data interface{}) {
+	yamlData, err := FormatYaml(data)
 	if err != nil {
 		fmt.Println("Failed to marshal data to YAML:", err)
 		os.Exit(1)
 	}
 
-	fmt.Println(string(yamlData))
+	fmt.Println(yamlData)
 }
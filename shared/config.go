@@ -7,5 +7,7 @@ var (
 	ElasticsearchPassword string
 	ElasticsearchHost     string
 	ElasticsearchPort     int
+	ReadOnly              bool
 	Debug                 bool
+	BundlePath            string
 )